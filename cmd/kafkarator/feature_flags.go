@@ -9,7 +9,8 @@ import (
 )
 
 type FeatureFlags struct {
-	GeneratedClient bool `split_words:"true"`
+	GeneratedClient    bool `split_words:"true"`
+	SchemaRegistryAcls bool `split_words:"true"`
 }
 
 func GetFeatureFlags() (*FeatureFlags, error) {
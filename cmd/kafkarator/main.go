@@ -9,11 +9,12 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/go-logr/logr"
 	"github.com/nais/kafkarator/pkg/aiven/acl"
 	"github.com/nais/kafkarator/pkg/aiven/adapter/aivengoclient"
 	"github.com/nais/kafkarator/pkg/aiven/adapter/goclientcodegen"
-	"github.com/nais/liberator/pkg/aiven/service"
+	"github.com/nais/kafkarator/pkg/aiven/adapter/saramaadmin"
 	"github.com/nais/liberator/pkg/logrus2logr"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
@@ -22,14 +23,23 @@ import (
 	generated_client "github.com/aiven/go-client-codegen"
 	"github.com/nais/kafkarator/controllers"
 	"github.com/nais/kafkarator/pkg/aiven"
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	"github.com/nais/kafkarator/pkg/deletion"
+	"github.com/nais/kafkarator/pkg/kafkapool"
 	kafkaratormetrics "github.com/nais/kafkarator/pkg/metrics"
 	"github.com/nais/kafkarator/pkg/metrics/collectors"
+	"github.com/nais/kafkarator/pkg/policy"
+	"github.com/nais/kafkarator/pkg/snapshot"
 	"github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
 	"github.com/nais/liberator/pkg/conftools"
 	log "github.com/sirupsen/logrus"
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -53,14 +63,30 @@ const (
 )
 
 const (
-	AivenToken          = "aiven-token"
-	LogFormat           = "log-format"
-	MetricsAddress      = "metrics-address"
-	Projects            = "projects"
-	RequeueInterval     = "requeue-interval"
-	SyncPeriod          = "sync-period"
-	TopicReportInterval = "topic-report-interval"
-	DryRun              = "dry-run"
+	AivenToken              = "aiven-token"
+	LogFormat               = "log-format"
+	MetricsAddress          = "metrics-address"
+	Projects                = "projects"
+	RequeueInterval         = "requeue-interval"
+	SyncPeriod              = "sync-period"
+	TopicReportInterval     = "topic-report-interval"
+	DryRun                  = "dry-run"
+	ACLConcurrency          = "acl-concurrency"
+	ACLConcurrencyOverrides = "acl-concurrency-overrides"
+	LocalPools              = "local-pools"
+	LocalTagsNamespace      = "local-tags-namespace"
+	Pools                   = "pools"
+	ServiceNameCacheTTL     = "service-name-cache-ttl"
+	CapabilitiesCacheTTL    = "capabilities-cache-ttl"
+	PolicyFile              = "policy-file"
+	ProfileConfigMap        = "profile-config-map"
+	ClusterName             = "cluster-name"
+	DeletionGracePeriod     = "deletion-grace-period"
+	DeletionCheckInterval   = "deletion-check-interval"
+	SnapshotNamespace       = "snapshot-namespace"
+	SnapshotRetention       = "snapshot-retention"
+	VerifyTimeout           = "verify-timeout"
+	ForceRelease            = "force-release"
 )
 
 const (
@@ -81,8 +107,24 @@ func init() {
 	flag.Duration(TopicReportInterval, time.Minute*5, "The interval for topic metrics reporting")
 	flag.Duration(RequeueInterval, time.Minute*5, "Requeueing interval when topic synchronization to Aiven fails")
 	flag.Duration(SyncPeriod, time.Hour*1, "How often to re-synchronize all Topic resources including credential rotation")
-	flag.StringSlice(Projects, []string{"dev-nais-dev"}, "List of projects allowed to operate on")
+	flag.StringSlice(Projects, []string{"dev-nais-dev"}, "List of pools allowed to operate on, in addition to pools declared as KafkaPool resources")
 	flag.Bool(DryRun, false, "If true, do not make any changes")
+	flag.Int(ACLConcurrency, 4, "Maximum number of parallel ACL create or delete calls per resource")
+	flag.StringSlice(ACLConcurrencyOverrides, []string{}, "Per-project ACL concurrency on the form project=N")
+	flag.StringSlice(Pools, []string{}, "Kafka services for pools on the form pool=project/service; other pools use the Kafka service of the project with the same name")
+	flag.Duration(ServiceNameCacheTTL, time.Minute*10, "How long to cache the Kafka service name of a project")
+	flag.Duration(CapabilitiesCacheTTL, time.Minute*10, "How long to cache the node count, plan and configuration of the Kafka service of a pool, used to validate topics")
+	flag.String(PolicyFile, "", "Path to a topic configuration policy, reloaded when changed; no policy is applied if empty")
+	flag.String(ClusterName, "", "Name of this cluster, written as a tag on topics in Aiven to tell which cluster manages them")
+	flag.String(ProfileConfigMap, "", "ConfigMap with topic configuration profiles for each pool, on the form namespace/name; profiles are not available if empty")
+	flag.Duration(DeletionGracePeriod, 0, "How long to keep the data of deleted topics before deleting it; data is deleted at once if zero")
+	flag.Duration(DeletionCheckInterval, time.Minute*5, "How often to delete the data of deleted topics when their grace period has passed")
+	flag.String(SnapshotNamespace, "", "Namespace to save snapshots of topics in before they are deleted; no snapshots are saved if empty")
+	flag.Duration(SnapshotRetention, time.Hour*24*30, "How long to keep snapshots of deleted topics")
+	flag.Duration(VerifyTimeout, time.Second*30, "How long to wait for a synchronized topic to become active in Aiven before retrying later")
+	flag.Bool(ForceRelease, false, "Allow the force release annotation; only enable when an admission policy restricts it to administrators")
+	flag.StringSlice(LocalPools, []string{}, "Manage plain Kafka clusters instead of Aiven, with bootstrap brokers for each pool on the form pool=host:port")
+	flag.String(LocalTagsNamespace, "", "Namespace to keep the tags of topics in local pools in; tags are kept in memory and lost on restart if empty")
 
 	flag.Parse()
 
@@ -145,6 +187,11 @@ func main() {
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Cache: cache.Options{
 			SyncPeriod: &syncPeriod,
+			// Only schema ConfigMaps are watched by the manager; the profile ConfigMap has a cache of its own,
+			// and other ConfigMaps are read directly from the API server.
+			ByObject: map[ctrl_client.Object]cache.ByObject{
+				&corev1.ConfigMap{}: {Label: labels.SelectorFromSet(labels.Set{controllers.SchemaConfigMapLabel: "true"})},
+			},
 		},
 		Client: ctrl_client.Options{
 			DryRun: new(viper.GetBool(DryRun)),
@@ -191,41 +238,135 @@ func main() {
 }
 
 func startReconcilers(quit QuitChannel, logger *log.Logger, featureFlags *FeatureFlags, mgr manager.Manager) {
-	aivenClient, err := aiven.NewTokenClient(viper.GetString(AivenToken), "")
+	aclConcurrency, err := acl.ParseConcurrency(viper.GetInt(ACLConcurrency), viper.GetStringSlice(ACLConcurrencyOverrides))
 	if err != nil {
-		quit <- fmt.Errorf("unable to set up aiven client: %s", err)
+		quit <- err
 		return
 	}
 
-	var aclClient acl.Interface
-	if featureFlags.GeneratedClient {
-		generatedClient, err := generated_client.NewClient(generated_client.TokenOpt(viper.GetString(AivenToken)))
+	var interfaces kafkarator_aiven.Interfaces
+	var aivenClient *aiven.Client
+	if localPools := viper.GetStringSlice(LocalPools); len(localPools) > 0 {
+		interfaces, err = localInterfaces(localPools, mgr)
+		if err != nil {
+			quit <- err
+			return
+		}
+		logger.Warnf("Managing local Kafka clusters instead of Aiven")
+	} else {
+		aivenClient, err = aiven.NewTokenClient(viper.GetString(AivenToken), "")
 		if err != nil {
 			quit <- fmt.Errorf("unable to set up aiven client: %s", err)
 			return
 		}
-		aclClient = &goclientcodegen.AclClient{
-			Client: generatedClient,
+		interfaces, err = aivenInterfaces(aivenClient, featureFlags)
+		if err != nil {
+			quit <- err
+			return
 		}
-	} else {
-		aclClient = &aivengoclient.AclClient{
-			KafkaACLHandler: aivenClient.KafkaACLs,
+		interfaces.Pools, err = kafkarator_aiven.ParsePools(viper.GetStringSlice(Pools))
+		if err != nil {
+			quit <- err
+			return
 		}
 	}
 
-	nameResolver := service.NewCachedNameResolver(aivenClient.Services)
+	var policyStore *policy.Store
+	if path := viper.GetString(PolicyFile); len(path) > 0 {
+		policyStore, err = policy.NewStore(path, logger)
+		if err != nil {
+			quit <- err
+			return
+		}
+		if err = mgr.Add(policyStore); err != nil {
+			quit <- fmt.Errorf("unable to watch policy file: %s", err)
+			return
+		}
+	}
+
+	profileConfigMap, err := parseNamespacedName(viper.GetString(ProfileConfigMap))
+	if err != nil {
+		quit <- fmt.Errorf("invalid %s: %s", ProfileConfigMap, err)
+		return
+	}
+
+	// The manager only caches schema ConfigMaps, so the profile ConfigMap has a cache of its own.
+	var profileCache cache.Cache
+	if len(profileConfigMap.Name) > 0 {
+		profileCache, err = cache.New(mgr.GetConfig(), cache.Options{
+			Scheme:            mgr.GetScheme(),
+			Mapper:            mgr.GetRESTMapper(),
+			DefaultNamespaces: map[string]cache.Config{profileConfigMap.Namespace: {}},
+			ByObject: map[ctrl_client.Object]cache.ByObject{
+				&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", profileConfigMap.Name)},
+			},
+		})
+		if err == nil {
+			err = mgr.Add(profileCache)
+		}
+		if err != nil {
+			quit <- fmt.Errorf("unable to watch profile ConfigMap: %s", err)
+			return
+		}
+	}
+
+	pools := &kafkapool.Registry{
+		Reader:   mgr.GetClient(),
+		Aiven:    interfaces,
+		Projects: viper.GetStringSlice(Projects),
+	}
+
+	// Plain Kafka has no topic tags to keep the deletion deadline in, so local pools delete data at once.
+	var reaper *deletion.Reaper
+	gracePeriod := viper.GetDuration(DeletionGracePeriod)
+	if gracePeriod > 0 && aivenClient == nil {
+		logger.Warnf("Ignoring %s for local Kafka clusters", DeletionGracePeriod)
+		gracePeriod = 0
+	}
+	if gracePeriod > 0 {
+		reaper = &deletion.Reaper{
+			Pools:       pools,
+			Topics:      interfaces.Topics,
+			ClusterName: viper.GetString(ClusterName),
+			Interval:    viper.GetDuration(DeletionCheckInterval),
+			Logger:      logger.WithField("component", "deletion-reaper"),
+			DryRun:      viper.GetBool(DryRun),
+		}
+		if err = mgr.Add(reaper); err != nil {
+			quit <- fmt.Errorf("unable to start deletion reaper: %s", err)
+			return
+		}
+	}
+
+	var snapshots snapshot.Store
+	if namespace := viper.GetString(SnapshotNamespace); len(namespace) > 0 {
+		snapshots = &snapshot.ConfigMapStore{
+			Client:    mgr.GetClient(),
+			Reader:    mgr.GetAPIReader(),
+			Namespace: namespace,
+			Retention: viper.GetDuration(SnapshotRetention),
+			Logger:    logger,
+		}
+	}
 
 	topicReconciler := &controllers.TopicReconciler{
-		Aiven: kafkarator_aiven.Interfaces{
-			ACLs:         aclClient,
-			Topics:       aivenClient.KafkaTopics,
-			NameResolver: nameResolver,
-		},
-		Client:          mgr.GetClient(),
-		Logger:          logger,
-		Projects:        viper.GetStringSlice(Projects),
-		RequeueInterval: viper.GetDuration(RequeueInterval),
-		DryRun:          viper.GetBool(DryRun),
+		Aiven:               interfaces,
+		Client:              mgr.GetClient(),
+		Logger:              logger,
+		Projects:            viper.GetStringSlice(Projects),
+		RequeueInterval:     viper.GetDuration(RequeueInterval),
+		DryRun:              viper.GetBool(DryRun),
+		ACLConcurrency:      aclConcurrency,
+		Policy:              policyStore,
+		ProfileConfigMap:    profileConfigMap,
+		ProfileCache:        profileCache,
+		ClusterName:         viper.GetString(ClusterName),
+		Recorder:            mgr.GetEventRecorder("kafkarator"),
+		DeletionGracePeriod: gracePeriod,
+		Reaper:              reaper,
+		VerifyTimeout:       viper.GetDuration(VerifyTimeout),
+		Snapshots:           snapshots,
+		ForceRelease:        viper.GetBool(ForceRelease),
 	}
 	if err = topicReconciler.SetupWithManager(mgr); err != nil {
 		quit <- fmt.Errorf("unable to set up topicReconciler: %s", err)
@@ -233,16 +374,17 @@ func startReconcilers(quit QuitChannel, logger *log.Logger, featureFlags *Featur
 	}
 
 	streamReconciler := &controllers.StreamReconciler{
-		Client: mgr.GetClient(),
-		Aiven: kafkarator_aiven.Interfaces{
-			ACLs:         aclClient,
-			Topics:       aivenClient.KafkaTopics,
-			NameResolver: nameResolver,
-		},
+		Client:          mgr.GetClient(),
+		Aiven:           interfaces,
 		Logger:          logger,
 		Projects:        viper.GetStringSlice(Projects),
 		RequeueInterval: viper.GetDuration(RequeueInterval),
 		DryRun:          viper.GetBool(DryRun),
+		ACLConcurrency:  aclConcurrency,
+		Recorder:        mgr.GetEventRecorder("kafkarator"),
+		Snapshots:       snapshots,
+		ClusterName:     viper.GetString(ClusterName),
+		ForceRelease:    viper.GetBool(ForceRelease),
 	}
 	if err = streamReconciler.SetupWithManager(mgr); err != nil {
 		quit <- fmt.Errorf("unable to set up streamReconciler: %s", err)
@@ -251,16 +393,94 @@ func startReconcilers(quit QuitChannel, logger *log.Logger, featureFlags *Featur
 
 	logger.Info("Reconcilers started")
 
+	// The metric collectors report on Aiven projects, and are not available for local Kafka clusters.
+	if aivenClient == nil {
+		return
+	}
+
 	collectors.Start(&collectors.Opts{
 		Client:         mgr.GetClient(),
 		AivenClient:    aivenClient,
 		ReportInterval: viper.GetDuration(TopicReportInterval),
-		Projects:       viper.GetStringSlice(Projects),
-		NameResolver:   nameResolver,
+		Pools:          pools,
+		Capabilities:   interfaces.Capabilities,
 		Logger:         logger,
 	})
 }
 
+// parseNamespacedName parses a reference on the form namespace/name. An empty reference is allowed.
+func parseNamespacedName(reference string) (types.NamespacedName, error) {
+	if len(reference) == 0 {
+		return types.NamespacedName{}, nil
+	}
+	namespace, name, ok := strings.Cut(reference, "/")
+	if !ok || len(namespace) == 0 || len(name) == 0 {
+		return types.NamespacedName{}, fmt.Errorf("expected namespace/name, got '%s'", reference)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+func aivenInterfaces(aivenClient *aiven.Client, featureFlags *FeatureFlags) (kafkarator_aiven.Interfaces, error) {
+	var aclClient acl.Interface
+	var schemaRegistryAclClient acl.SchemaRegistryInterface
+	if featureFlags.GeneratedClient {
+		generatedClient, err := generated_client.NewClient(generated_client.TokenOpt(viper.GetString(AivenToken)))
+		if err != nil {
+			return kafkarator_aiven.Interfaces{}, fmt.Errorf("unable to set up aiven client: %s", err)
+		}
+		aclClient = &goclientcodegen.AclClient{
+			Client: generatedClient,
+		}
+		if featureFlags.SchemaRegistryAcls {
+			schemaRegistryAclClient = &goclientcodegen.SchemaRegistryAclClient{
+				Client: generatedClient,
+			}
+		}
+	} else {
+		aclClient = &aivengoclient.AclClient{
+			KafkaACLHandler: aivenClient.KafkaACLs,
+		}
+		if featureFlags.SchemaRegistryAcls {
+			schemaRegistryAclClient = &aivengoclient.SchemaRegistryAclClient{
+				KafkaSchemaRegistryACLHandler: aivenClient.KafkaSchemaRegistryACLs,
+			}
+		}
+	}
+
+	return kafkarator_aiven.Interfaces{
+		ACLs:               aclClient,
+		Topics:             aivenClient.KafkaTopics,
+		NameResolver:       kafkarator_aiven.NewExpiringNameResolver(aivenClient.Services, viper.GetDuration(ServiceNameCacheTTL)),
+		SchemaRegistryACLs: schemaRegistryAclClient,
+		Schemas:            aivenClient.KafkaSubjectSchemas,
+		Capabilities:       kafkarator_aiven.NewCapabilitiesCache(aivenClient.Services, viper.GetDuration(CapabilitiesCacheTTL)),
+	}, nil
+}
+
+// localInterfaces manages plain Kafka clusters through the Kafka admin API, for local development.
+// Schema registry features are not available.
+func localInterfaces(localPools []string, mgr manager.Manager) (kafkarator_aiven.Interfaces, error) {
+	pools, err := saramaadmin.ParsePools(localPools)
+	if err != nil {
+		return kafkarator_aiven.Interfaces{}, err
+	}
+
+	admins := saramaadmin.NewAdmins(sarama.NewConfig())
+	topics := &saramaadmin.TopicClient{Admins: admins}
+	if namespace := viper.GetString(LocalTagsNamespace); len(namespace) > 0 {
+		topics.Tags = &saramaadmin.ConfigMapTagStore{
+			Client:    mgr.GetClient(),
+			Reader:    mgr.GetAPIReader(),
+			Namespace: namespace,
+		}
+	}
+	return kafkarator_aiven.Interfaces{
+		ACLs:         &saramaadmin.AclClient{Admins: admins},
+		Topics:       topics,
+		NameResolver: &saramaadmin.NameResolver{Pools: pools},
+	}, nil
+}
+
 func init() {
 	err := clientgoscheme.AddToScheme(scheme)
 	if err != nil {
@@ -272,6 +492,11 @@ func init() {
 		panic(err)
 	}
 
+	err = kafkarator_nais_io_v1alpha1.AddToScheme(scheme)
+	if err != nil {
+		panic(err)
+	}
+
 	kafkaratormetrics.Register(metrics.Registry)
 	// +kubebuilder:scaffold:scheme
 }
@@ -0,0 +1,146 @@
+// Command kafkaratorctl is a command line tool for operators of Kafkarator.
+//
+// Usage:
+//
+//	kafkaratorctl export --project <project> [--service <service>] [--pool <pool>] > topics.yaml
+//	kafkaratorctl restore --snapshot <name> --snapshot-namespace <namespace>
+//
+// The export command writes a Topic resource for every topic in an Aiven Kafka service, with the configuration and
+// ACLs of the topic. Settings and ACLs that a Topic can not represent are written as comments.
+//
+// The restore command recreates a deleted topic and its ACLs from a snapshot ConfigMap, as saved by Kafkarator
+// before topics are deleted. The data of the topic is not restored.
+//
+// The Aiven API token is read from --aiven-token or KAFKARATOR_AIVEN_TOKEN.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/aiven/adapter/aivengoclient"
+	"github.com/nais/kafkarator/pkg/export"
+	"github.com/nais/liberator/pkg/aiven/service"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	ExitOK = iota
+	ExitConfig
+	ExitRuntime
+)
+
+const (
+	AivenToken = "aiven-token"
+	Project    = "project"
+	Service    = "service"
+	Pool       = "pool"
+	Timeout    = "timeout"
+)
+
+const usage = `Usage: kafkaratorctl <command> [flags]
+
+Commands:
+  export    Write Topic resources for the topics and ACLs in an Aiven Kafka service
+  restore   Recreate a deleted topic and its ACLs from a snapshot
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(ExitConfig)
+	}
+
+	switch os.Args[1] {
+	case "export":
+		os.Exit(exportTopics(os.Args[2:]))
+	case "restore":
+		os.Exit(restoreTopic(os.Args[2:]))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		os.Exit(ExitOK)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n%s", os.Args[1], usage)
+		os.Exit(ExitConfig)
+	}
+}
+
+// config parses the flags of a command. Flags can also be set as environment variables,
+// i.e. --aiven-token is configurable using KAFKARATOR_AIVEN_TOKEN.
+func config(flags *flag.FlagSet, args []string) (*viper.Viper, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	v := viper.New()
+	v.SetEnvPrefix("KAFKARATOR")
+	v.AutomaticEnv()
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	if err := v.BindPFlags(flags); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func exportTopics(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.String(AivenToken, "", "API token for Aiven with access to the project")
+	flags.String(Project, "", "Aiven project to export topics from")
+	flags.String(Service, "", "Kafka service in the project; the Kafka service of the project is used if empty")
+	flags.String(Pool, "", "Pool of the generated topics; the project is used if empty")
+	flags.Duration(Timeout, time.Minute*5, "Maximum duration of the export")
+
+	cfg, err := config(flags, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitConfig
+	}
+	project := cfg.GetString(Project)
+	if len(project) == 0 {
+		fmt.Fprintf(os.Stderr, "--%s is required\n", Project)
+		return ExitConfig
+	}
+	pool := cfg.GetString(Pool)
+	if len(pool) == 0 {
+		pool = project
+	}
+
+	client, err := aiven.NewTokenClient(cfg.GetString(AivenToken), "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to set up aiven client: %s\n", err)
+		return ExitConfig
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.GetDuration(Timeout))
+	defer cancel()
+
+	serviceName := cfg.GetString(Service)
+	if len(serviceName) == 0 {
+		serviceName, err = service.NewCachedNameResolver(client.Services).ResolveKafkaServiceName(ctx, project)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to find the Kafka service of project '%s': %s\n", project, err)
+			return ExitRuntime
+		}
+	}
+
+	exporter := &export.Exporter{
+		Topics:  client.KafkaTopics,
+		ACLs:    &aivengoclient.AclClient{KafkaACLHandler: client.KafkaACLs},
+		Project: project,
+		Service: serviceName,
+		Pool:    pool,
+	}
+	manifests, err := exporter.Export(ctx)
+	if err == nil {
+		err = export.Write(os.Stdout, manifests)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitRuntime
+	}
+	return ExitOK
+}
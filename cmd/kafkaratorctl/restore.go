@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/aiven/adapter/aivengoclient"
+	"github.com/nais/kafkarator/pkg/snapshot"
+	log "github.com/sirupsen/logrus"
+	flag "github.com/spf13/pflag"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	Snapshot          = "snapshot"
+	SnapshotNamespace = "snapshot-namespace"
+)
+
+func restoreTopic(args []string) int {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.String(AivenToken, "", "API token for Aiven with access to the project of the snapshot")
+	flags.String(Snapshot, "", "Name of the snapshot ConfigMap to restore")
+	flags.String(SnapshotNamespace, "", "Namespace of the snapshot ConfigMaps")
+	flags.Duration(Timeout, time.Minute, "Maximum duration of the restore")
+
+	cfg, err := config(flags, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitConfig
+	}
+	for _, required := range []string{Snapshot, SnapshotNamespace} {
+		if len(cfg.GetString(required)) == 0 {
+			fmt.Fprintf(os.Stderr, "--%s is required\n", required)
+			return ExitConfig
+		}
+	}
+
+	kubeconfig, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to find kubeconfig: %s\n", err)
+		return ExitConfig
+	}
+	kubeClient, err := client.New(kubeconfig, client.Options{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to set up kubernetes client: %s\n", err)
+		return ExitConfig
+	}
+	aivenClient, err := aiven.NewTokenClient(cfg.GetString(AivenToken), "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to set up aiven client: %s\n", err)
+		return ExitConfig
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.GetDuration(Timeout))
+	defer cancel()
+
+	store := &snapshot.ConfigMapStore{
+		Client:    kubeClient,
+		Reader:    kubeClient,
+		Namespace: cfg.GetString(SnapshotNamespace),
+	}
+	s, err := store.Get(ctx, cfg.GetString(Snapshot))
+	if err == nil {
+		err = snapshot.Restore(ctx, aivenClient.KafkaTopics, &aivengoclient.AclClient{KafkaACLHandler: aivenClient.KafkaACLs}, s, log.New())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitRuntime
+	}
+	return ExitOK
+}
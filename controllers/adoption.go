@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/aiven/acl"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+)
+
+// adoptedTopic is the state of a topic in Aiven before it was adopted by Kafkarator.
+type adoptedTopic struct {
+	Partitions  int            `json:"partitions"`
+	Replication int            `json:"replication"`
+	Config      map[string]any `json:"config,omitempty"`
+	ACLs        []string       `json:"acls,omitempty"`
+}
+
+// adoptionRequiredError is returned for topics in Aiven not managed by Kafkarator, until adoption is requested.
+type adoptionRequiredError struct {
+	topic   string
+	changes []string
+}
+
+func (e *adoptionRequiredError) Error() string {
+	if len(e.changes) == 0 {
+		return fmt.Sprintf("topic '%s' exists in Aiven, but is not managed by Kafkarator; it already matches the spec", e.topic)
+	}
+	return fmt.Sprintf("topic '%s' exists in Aiven, but is not managed by Kafkarator; adopting it changes %s", e.topic, strings.Join(e.changes, ", "))
+}
+
+func adopt(topic kafka_nais_io_v1.Topic) bool {
+	return topic.Annotations[AdoptAnnotation] == "true"
+}
+
+// checkAdoption refuses to synchronize topics created outside Kafkarator unless adoption is requested.
+// Adopted topics have their state before adoption recorded in Adopted.
+func (c *Synchronizer) checkAdoption(ctx context.Context) error {
+	existing, changes, err := c.Topics.Unmanaged(ctx)
+	if err != nil || existing == nil {
+		return err
+	}
+	aclChanges, err := c.ACLs.Plan(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range aclChanges.Add {
+		changes = append(changes, fmt.Sprintf("create ACL %s %s", a.Username, a.Permission))
+	}
+	for _, a := range aclChanges.Delete {
+		changes = append(changes, fmt.Sprintf("delete ACL %s %s", a.Username, a.Permission))
+	}
+
+	if !c.Adopt {
+		return &adoptionRequiredError{topic: existing.TopicName, changes: changes}
+	}
+
+	c.Logger.Infof("Adopting topic created outside Kafkarator")
+	c.Adopted, err = recordAdoption(existing, aclChanges.Existing)
+	return err
+}
+
+func recordAdoption(existing *aiven.KafkaTopic, acls []acl.Acl) (*adoptedTopic, error) {
+	config, err := configValues(existing.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	adopted := &adoptedTopic{
+		Partitions:  len(existing.Partitions),
+		Replication: existing.Replication,
+		Config:      config,
+	}
+	for _, a := range acls {
+		adopted.ACLs = append(adopted.ACLs, fmt.Sprintf("%s %s", a.Username, a.Permission))
+	}
+	return adopted, nil
+}
+
+// configValues returns the value of every setting of a topic, without the source and synonyms reported by Aiven.
+func configValues(config aiven.KafkaTopicConfigResponse) (map[string]any, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[string]struct {
+		Value any `json:"value"`
+	})
+	if err = json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(settings))
+	for name, setting := range settings {
+		values[name] = setting.Value
+	}
+	return values, nil
+}
+
+func adoptedTopicAnnotation(adopted *adoptedTopic) (string, error) {
+	data, err := json.Marshal(adopted)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// aivenState is a summary of a topic and its ACLs as they are in Aiven, so that teams can see what is configured.
+type aivenState struct {
+	State       string `json:"state"`
+	Partitions  int    `json:"partitions"`
+	Replication int    `json:"replication"`
+	// Config holds the effective value of every setting, including defaults not set on the topic.
+	Config map[string]any    `json:"config,omitempty"`
+	Tags   map[string]string `json:"tags,omitempty"`
+	ACLs   []aivenACL        `json:"acls,omitempty"`
+}
+
+type aivenACL struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	Permission string `json:"permission"`
+}
+
+// Observe reads the topic and its ACLs from Aiven, and summarizes them.
+func (c *Synchronizer) Observe(ctx context.Context) (*aivenState, error) {
+	existing, err := c.Topics.Existing(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("topic '%s' not found in Aiven", c.Topics.Topic.FullName())
+	}
+	acls, err := c.ACLs.Existing(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := configValues(existing.Config)
+	if err != nil {
+		return nil, err
+	}
+	state := &aivenState{
+		State:       existing.State,
+		Partitions:  len(existing.Partitions),
+		Replication: existing.Replication,
+		Config:      config,
+	}
+	if len(existing.Tags) > 0 {
+		state.Tags = make(map[string]string, len(existing.Tags))
+		for _, tag := range existing.Tags {
+			state.Tags[tag.Key] = tag.Value
+		}
+	}
+	for _, a := range acls {
+		state.ACLs = append(state.ACLs, aivenACL{ID: a.ID, Username: a.Username, Permission: a.Permission})
+	}
+	sort.Slice(state.ACLs, func(i, j int) bool {
+		if state.ACLs[i].Username != state.ACLs[j].Username {
+			return state.ACLs[i].Username < state.ACLs[j].Username
+		}
+		return state.ACLs[i].Permission < state.ACLs[j].Permission
+	})
+	return state, nil
+}
+
+func aivenStateAnnotation(state *aivenState) (string, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
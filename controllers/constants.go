@@ -1,3 +1,85 @@
 package controllers
 
 const Finalizer = "kafkarator.kafka.nais.io"
+
+const (
+	// EventFailedOwnership is the synchronization state of topics owned by another cluster in Aiven.
+	EventFailedOwnership = "FailedOwnership"
+
+	// EventAdoptionRequired is the synchronization state of topics created in Aiven outside Kafkarator, until adopted.
+	EventAdoptionRequired = "AdoptionRequired"
+
+	// EventDataLossNotAcknowledged is the synchronization state of topics with changes that delete data in Aiven,
+	// until the changes are acknowledged.
+	EventDataLossNotAcknowledged = "DataLossNotAcknowledged"
+
+	// EventWaitingOnFreeze is the synchronization state of resources with changes deferred by a change freeze
+	// of their pool, until the freeze ends.
+	EventWaitingOnFreeze = "WaitingOnFreeze"
+
+	// EventWaitingOnAiven is the synchronization state of topics that are synchronized, but not yet active with the
+	// wanted configuration in Aiven.
+	EventWaitingOnAiven = "WaitingOnAiven"
+
+	// EventPaused is the synchronization state of resources paused by the pause annotation.
+	EventPaused = "Paused"
+)
+
+const (
+	// SchemaConfigMapAnnotation names a ConfigMap in the topic namespace with schemas to register for the topic.
+	SchemaConfigMapAnnotation = "kafka.nais.io/schemaConfigMap"
+
+	// SchemaConfigMapLabel must be set on ConfigMaps with schemas, so that Kafkarator watches them and registers changes.
+	// Only labelled ConfigMaps are cached, and topics referring to unlabelled ones fail.
+	SchemaConfigMapLabel = "kafka.nais.io/schemas"
+
+	// ProfileAnnotation names a topic configuration profile of the topic's pool, used for settings not set on the topic.
+	ProfileAnnotation = "kafka.nais.io/profile"
+
+	// ExtraConfigAnnotation holds topic configuration not modelled in the Topic spec, as a YAML or JSON map
+	// keyed by the Kafka configuration name, i.e. {"compression.type": "zstd"}. Only allowlisted settings are accepted.
+	ExtraConfigAnnotation = "kafka.nais.io/extraConfig"
+
+	// TakeoverAnnotation set to "true" allows changing and deleting a topic in Aiven owned by another cluster.
+	// The topic is tagged as owned by this cluster when synchronized.
+	TakeoverAnnotation = "kafka.nais.io/takeover"
+
+	// AdoptAnnotation set to "true" allows Kafkarator to take over a topic created in Aiven outside Kafkarator.
+	// Until then, synchronization is refused and the status lists the changes adoption would make.
+	AdoptAnnotation = "kafka.nais.io/adopt"
+
+	// AdoptedTopicAnnotation is written by Kafkarator when adopting a topic, and holds the partitions, replication,
+	// configuration and ACLs of the topic in Aiven before adoption.
+	AdoptedTopicAnnotation = "kafkarator.kafka.nais.io/adoptedTopic"
+
+	// AcknowledgeDataLossAnnotation allows changes to a topic that delete data in Aiven, such as lower retention.
+	// The value must be the synchronization hash of the changed spec, as given in the status when the change is refused.
+	AcknowledgeDataLossAnnotation = "kafka.nais.io/acknowledgeDataLoss"
+
+	// PausedAnnotation set to "true" pauses reconciliation of a Topic or Stream, leaving it alone in Aiven.
+	// Deleted resources keep their finalizer until resumed.
+	PausedAnnotation = "kafka.nais.io/paused"
+
+	// DeletionPolicyAnnotation selects what happens in Aiven when a Topic or Stream is deleted;
+	// one of delete (the default), orphan or retain-acls.
+	DeletionPolicyAnnotation = "kafka.nais.io/deletionPolicy"
+
+	// ForceReleaseAnnotation set to "true" by an administrator removes the finalizer of a deleted Topic or Stream
+	// without any calls to Aiven, i.e. when its pool or Aiven service is gone.
+	ForceReleaseAnnotation = "kafkarator.kafka.nais.io/forceRelease"
+
+	// RegisteredSchemasAnnotation is written by Kafkarator, and holds the schema id and version of each registered subject.
+	RegisteredSchemasAnnotation = "kafkarator.kafka.nais.io/registeredSchemas"
+
+	// SynchronizationPhasesAnnotation is written by Kafkarator, and holds the outcome and time of each phase of the
+	// latest synchronization of a topic, as a JSON list.
+	SynchronizationPhasesAnnotation = "kafkarator.kafka.nais.io/synchronizationPhases"
+
+	// AivenStateAnnotation is written by Kafkarator after each successful synchronization, and holds the state,
+	// partitions, replication, effective configuration and tags of the topic in Aiven, and its ACLs with their IDs.
+	// Topics are not read from Aiven while their spec is unchanged, so the summary reflects the latest synchronization.
+	AivenStateAnnotation = "kafkarator.kafka.nais.io/aivenState"
+
+	// PolicyWarningsAnnotation is written by Kafkarator, and lists the policy rules in warn mode violated by the topic.
+	PolicyWarningsAnnotation = "kafkarator.kafka.nais.io/policyWarnings"
+)
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Deletion policies of Topic and Stream resources, set with the DeletionPolicyAnnotation.
+const (
+	// DeletionPolicyDelete deletes ACLs, and data if requested, from Aiven before the finalizer is removed.
+	DeletionPolicyDelete = "delete"
+	// DeletionPolicyOrphan removes the finalizer without any calls to Aiven.
+	DeletionPolicyOrphan = "orphan"
+	// DeletionPolicyRetainACLs deletes the data from Aiven, but keeps the ACLs.
+	DeletionPolicyRetainACLs = "retain-acls"
+)
+
+// Reasons of the events emitted when a resource is released without the default deletion policy.
+const (
+	EventReasonOrphaned      = "Orphaned"
+	EventReasonForceReleased = "ForceReleased"
+	EventReasonRetainedACLs  = "RetainedACLs"
+	// EventReasonForceReleaseDisabled is emitted when the ForceReleaseAnnotation is ignored.
+	EventReasonForceReleaseDisabled = "ForceReleaseDisabled"
+)
+
+// deletionPolicy returns the deletion policy of a Topic or Stream.
+func deletionPolicy(obj client.Object) (string, error) {
+	policy := obj.GetAnnotations()[DeletionPolicyAnnotation]
+	switch policy {
+	case "":
+		return DeletionPolicyDelete, nil
+	case DeletionPolicyDelete, DeletionPolicyOrphan, DeletionPolicyRetainACLs:
+		return policy, nil
+	}
+	return "", fmt.Errorf("annotation '%s': unknown deletion policy '%s'; expected %s, %s or %s",
+		DeletionPolicyAnnotation, policy, DeletionPolicyDelete, DeletionPolicyOrphan, DeletionPolicyRetainACLs)
+}
+
+// release returns a status message if a deleted resource should be released without any calls to Aiven,
+// either by the orphan deletion policy, or by an administrator setting the ForceReleaseAnnotation.
+// The annotation is ignored unless forceRelease is set, as only an admission policy can restrict it to administrators.
+// Every release is logged and emitted as an event.
+func release(obj client.Object, forceRelease bool, recorder events.EventRecorder, logger log.FieldLogger) (string, bool) {
+	forced := obj.GetAnnotations()[ForceReleaseAnnotation] == "true"
+	if forced && !forceRelease {
+		message := fmt.Sprintf("Ignoring the %s annotation, as force release is not enabled in this cluster", ForceReleaseAnnotation)
+		logger.Warn(message)
+		emit(recorder, obj, corev1.EventTypeWarning, EventReasonForceReleaseDisabled, "Delete", message)
+	}
+
+	var reason, message string
+	switch {
+	case forced && forceRelease:
+		reason, message = EventReasonForceReleased, "Finalizer removed by force release; resources in Aiven are left as they are"
+	case obj.GetAnnotations()[DeletionPolicyAnnotation] == DeletionPolicyOrphan:
+		reason, message = EventReasonOrphaned, "Finalizer removed by the orphan deletion policy; resources in Aiven are left as they are"
+	default:
+		return "", false
+	}
+	logger.Warn(message)
+	emit(recorder, obj, corev1.EventTypeWarning, reason, "Delete", message)
+	return message, true
+}
+
+// emit records an event for the resource, unless there is no event recorder.
+func emit(recorder events.EventRecorder, obj runtime.Object, eventType, reason, action, message string) {
+	if recorder != nil {
+		recorder.Eventf(obj, nil, eventType, reason, action, "%s", message)
+	}
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/utils"
+)
+
+// joinedErrors returns the individual errors of a joined error, such as the one returned
+// when several parallel Aiven calls fail. Returns nil if the error was not joined.
+func joinedErrors(err error) []error {
+	for err != nil {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			return joined.Unwrap()
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
+
+// statusErrors formats each failed call as its own entry in the resource status.
+func statusErrors(errs []error) []string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		var aivenError aiven.Error
+		switch {
+		case !errors.As(err, &aivenError):
+			messages = append(messages, utils.CheckForPossibleCredentials(err).Error())
+		case aivenError.Status == http.StatusOK:
+			messages = append(messages, "unknown error while calling Aiven API")
+		default:
+			messages = append(messages, fmt.Sprintf("%s: %s", aivenError.Message, aivenError.MoreInfo))
+		}
+	}
+	return messages
+}
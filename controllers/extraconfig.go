@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/nais/liberator/pkg/hash"
+)
+
+// extraConfig reads the topic configuration set through ExtraConfigAnnotation.
+// Returns nil if the topic does not set any extra configuration.
+func extraConfig(t kafka_nais_io_v1.Topic) (topic.ExtraConfig, error) {
+	data := t.Annotations[ExtraConfigAnnotation]
+	if len(data) == 0 {
+		return nil, nil
+	}
+	extra, err := topic.ParseExtraConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("annotation '%s': %w", ExtraConfigAnnotation, err)
+	}
+	return extra, nil
+}
+
+// hashWithExtraConfig extends the topic synchronization hash with the parsed extra configuration,
+// so that only changes to the effective settings cause synchronization.
+func hashWithExtraConfig(topicHash string, extra topic.ExtraConfig) (string, error) {
+	if len(extra) == 0 {
+		return topicHash, nil
+	}
+	return hash.Hash(struct {
+		Topic       string
+		ExtraConfig topic.ExtraConfig
+	}{
+		Topic:       topicHash,
+		ExtraConfig: extra,
+	})
+}
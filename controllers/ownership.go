@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+
+	topic_package "github.com/nais/kafkarator/pkg/aiven/topic"
+	"github.com/nais/kafkarator/pkg/freeze"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+)
+
+// synchronizationFailure returns the error, synchronization state and retry flag for a failed synchronization.
+// Ownership conflicts, topics needing adoption and unacknowledged data loss are not retried, as they need an annotation
+// on the topic, which triggers a new reconcile. Changes deferred by a freeze are retried when the freeze ends.
+func synchronizationFailure(err error) (error, string, bool) {
+	var conflict *topic_package.OwnershipConflictError
+	var adoption *adoptionRequiredError
+	var dataLoss *topic_package.DataLossError
+	var deferred *freeze.DeferredError
+	var notReady *topic_package.NotReadyError
+	switch {
+	case errors.As(err, &conflict):
+		return fmt.Errorf("%w; set the annotation %s: \"true\" to take over the topic", err, TakeoverAnnotation), EventFailedOwnership, false
+	case errors.As(err, &adoption):
+		return fmt.Errorf("%w; set the annotation %s: \"true\" to adopt the topic", err, AdoptAnnotation), EventAdoptionRequired, false
+	case errors.As(err, &dataLoss):
+		return fmt.Errorf("%w; set the annotation %s: \"%s\" to apply the changes", err, AcknowledgeDataLossAnnotation, dataLoss.Hash), EventDataLossNotAcknowledged, false
+	case errors.As(err, &deferred):
+		return err, EventWaitingOnFreeze, true
+	case errors.As(err, &notReady):
+		return err, EventWaitingOnAiven, true
+	default:
+		return err, kafka_nais_io_v1.EventFailedSynchronization, true
+	}
+}
+
+func takeover(topic kafka_nais_io_v1.Topic) bool {
+	return topic.Annotations[TakeoverAnnotation] == "true"
+}
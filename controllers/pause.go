@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"github.com/nais/kafkarator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const pausedMessage = "Reconciliation paused by the " + PausedAnnotation + " annotation"
+
+func paused(obj metav1.Object) bool {
+	return obj.GetAnnotations()[PausedAnnotation] == "true"
+}
+
+// reportPaused exports whether a resource is paused, so that forgotten pauses show up.
+func reportPaused(kind, namespace, name string, paused bool) {
+	labels := prometheus.Labels{
+		metrics.LabelKind: kind,
+		metrics.LabelTeam: namespace,
+		metrics.LabelName: name,
+	}
+	if paused {
+		metrics.PausedResources.With(labels).Set(1)
+	} else {
+		metrics.PausedResources.Delete(labels)
+	}
+}
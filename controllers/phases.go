@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+)
+
+// Synchronization phases of a topic, in the order they run.
+const (
+	// PhaseValidate checks ownership, adoption and data loss against the topic in Aiven. It only reads from Aiven,
+	// and always runs.
+	PhaseValidate = "validate"
+	// PhaseTopic creates or updates the topic, and registers its schemas.
+	PhaseTopic = "topic"
+	// PhaseACLs synchronizes the ACLs and schema registry ACLs of the topic.
+	PhaseACLs = "acls"
+	// PhaseVerify reads the topic back from Aiven, and checks that it matches the spec.
+	PhaseVerify = "verify"
+)
+
+// Outcomes of a synchronization phase.
+const (
+	PhaseComplete = "Complete"
+	PhaseFailed   = "Failed"
+	// PhaseDeferred phases have changes deferred by a change freeze of the pool.
+	PhaseDeferred = "Deferred"
+)
+
+// phaseStatus is the outcome of the latest run of a synchronization phase.
+type phaseStatus struct {
+	Phase string `json:"phase"`
+	State string `json:"state"`
+	// Hash of the inputs of the phase when it ran.
+	Hash    string `json:"hash"`
+	Time    string `json:"time"`
+	Message string `json:"message,omitempty"`
+}
+
+// phases are the outcomes of the phases of the latest synchronization, in the order they ran.
+type phases []phaseStatus
+
+func parsePhases(topic kafka_nais_io_v1.Topic) (phases, error) {
+	value := topic.Annotations[SynchronizationPhasesAnnotation]
+	if len(value) == 0 {
+		return nil, nil
+	}
+	var p phases
+	if err := json.Unmarshal([]byte(value), &p); err != nil {
+		return nil, fmt.Errorf("annotation '%s': %w", SynchronizationPhasesAnnotation, err)
+	}
+	return p, nil
+}
+
+func (p phases) annotation() (string, error) {
+	if len(p) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// incomplete returns true if the latest synchronization did not complete, so that the next one is a retry.
+func (p phases) incomplete() bool {
+	for _, status := range p {
+		if status.State != PhaseComplete {
+			return true
+		}
+	}
+	return false
+}
+
+// completed returns true if the phase completed with the same inputs.
+func (p phases) completed(phase, hash string) bool {
+	for _, status := range p {
+		if status.Phase == phase {
+			return status.State == PhaseComplete && status.Hash == hash
+		}
+	}
+	return false
+}
+
+// failed returns the phase that failed, if any.
+func (p phases) failed() (phaseStatus, bool) {
+	for _, status := range p {
+		if status.State == PhaseFailed {
+			return status, true
+		}
+	}
+	return phaseStatus{}, false
+}
+
+// set records the outcome of a phase, replacing the outcome of an earlier run.
+func (p *phases) set(phase, hash, state string, err error) {
+	status := phaseStatus{
+		Phase: phase,
+		State: state,
+		Hash:  hash,
+		Time:  time.Now().Format(time.RFC3339),
+	}
+	if err != nil {
+		status.Message = err.Error()
+	}
+	for i := range *p {
+		if (*p)[i].Phase == phase {
+			(*p)[i] = status
+			return
+		}
+	}
+	*p = append(*p, status)
+}
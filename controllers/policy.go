@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nais/kafkarator/pkg/metrics"
+	"github.com/nais/kafkarator/pkg/policy"
+	"github.com/nais/liberator/pkg/hash"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// checkPolicy counts the policy violations, and splits them into warnings and an error for enforced rules.
+func checkPolicy(pool string, violations []policy.Violation) ([]string, error) {
+	var enforced, warnings []string
+	for _, violation := range violations {
+		metrics.PolicyViolations.With(prometheus.Labels{
+			metrics.LabelPool: pool,
+			metrics.LabelRule: violation.Rule,
+			metrics.LabelMode: string(violation.Mode),
+		}).Inc()
+		if violation.Mode == policy.ModeEnforce {
+			enforced = append(enforced, violation.String())
+		} else {
+			warnings = append(warnings, violation.String())
+		}
+	}
+	if len(enforced) > 0 {
+		return warnings, fmt.Errorf("topic configuration violates policy: %s", strings.Join(enforced, "; "))
+	}
+	return warnings, nil
+}
+
+// hashWithPolicy extends the topic synchronization hash with the policy violations of the topic,
+// so that reloaded policies are applied even if the Topic resource is unchanged.
+func hashWithPolicy(topicHash string, violations []policy.Violation) (string, error) {
+	if len(violations) == 0 {
+		return topicHash, nil
+	}
+	return hash.Hash(struct {
+		Topic      string
+		Violations []policy.Violation
+	}{
+		Topic:      topicHash,
+		Violations: violations,
+	})
+}
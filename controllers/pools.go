@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	"github.com/nais/kafkarator/pkg/freeze"
+	"github.com/nais/kafkarator/pkg/kafkapool"
+	"github.com/nais/kafkarator/pkg/metrics"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// +kubebuilder:rbac:groups=kafkarator.nais.io,resources=kafkapools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kafkarator.nais.io,resources=kafkapools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// poolAccessFailure returns the synchronization state and retry policy for an error from checking pool access.
+// Rejected namespaces are counted, and are not retried until the resource changes.
+func poolAccessFailure(kind, namespace, pool string, err error) (string, bool) {
+	var notAllowed *kafkapool.NotAllowedError
+	if !errors.As(err, &notAllowed) {
+		return kafka_nais_io_v1.EventFailedSynchronization, true
+	}
+	metrics.PoolAccessRejected.With(prometheus.Labels{
+		metrics.LabelKind: kind,
+		metrics.LabelTeam: namespace,
+		metrics.LabelPool: pool,
+	}).Inc()
+	return kafka_nais_io_v1.EventFailedPrepare, false
+}
+
+// reportQuotaUsage exports the usage of every quota set for a namespace.
+func reportQuotaUsage(namespace, pool string, usage kafkapool.Usage, quota *kafkarator_nais_io_v1alpha1.NamespaceQuota) {
+	report := func(resource string, used float64, limit float64) {
+		labels := prometheus.Labels{
+			metrics.LabelTeam:     namespace,
+			metrics.LabelPool:     pool,
+			metrics.LabelResource: resource,
+		}
+		metrics.PoolQuotaUsage.With(labels).Set(used)
+		metrics.PoolQuotaLimit.With(labels).Set(limit)
+	}
+	if quota.MaxTopics != nil {
+		report(metrics.ResourceTopics, float64(usage.Topics), float64(*quota.MaxTopics))
+	}
+	if quota.MaxPartitions != nil {
+		report(metrics.ResourcePartitions, float64(usage.Partitions), float64(*quota.MaxPartitions))
+	}
+	if quota.MaxRetainedBytes != nil {
+		report(metrics.ResourceRetainedBytes, float64(usage.RetainedBytes), float64(*quota.MaxRetainedBytes))
+	}
+}
+
+// maintenanceError returns an error if the pool is in maintenance.
+func maintenanceError(kafkaPool *kafkarator_nais_io_v1alpha1.KafkaPool) error {
+	if kafkaPool == nil || !kafkaPool.InMaintenance() {
+		return nil
+	}
+	if len(kafkaPool.Spec.Maintenance.Reason) > 0 {
+		return fmt.Errorf("pool '%s' is in maintenance: %s", kafkaPool.Name, kafkaPool.Spec.Maintenance.Reason)
+	}
+	return fmt.Errorf("pool '%s' is in maintenance", kafkaPool.Name)
+}
+
+// activeFreeze returns the change freeze in effect for the pool, or nil if changes are allowed.
+func activeFreeze(kafkaPool *kafkarator_nais_io_v1alpha1.KafkaPool) (*freeze.Freeze, error) {
+	if kafkaPool == nil {
+		return nil, nil
+	}
+	return kafkaPool.Frozen(time.Now())
+}
+
+// freezeRequeue returns when to retry changes deferred by a freeze, or zero if the error is not from a freeze.
+// Freezes without a window are retried at the requeue interval, or when the pool changes.
+func freezeRequeue(err error, requeueInterval time.Duration) time.Duration {
+	var deferred *freeze.DeferredError
+	if !errors.As(err, &deferred) {
+		return 0
+	}
+	return deferred.Freeze.RequeueAfter(time.Now(), requeueInterval)
+}
+
+// checkCapabilities returns an error if the topic configuration needs more than the Kafka service of the pool provides,
+// so that it is refused before anything is changed. Topics are not checked if the capabilities cannot be read.
+func checkCapabilities(ctx context.Context, capabilities *kafkarator_aiven.CapabilitiesCache, pool kafkarator_aiven.Pool, cfg *kafka_nais_io_v1.Config, logger log.FieldLogger) error {
+	if capabilities == nil {
+		return nil
+	}
+	c, err := capabilities.Get(ctx, pool)
+	if err != nil {
+		logger.Warnf("Unable to read the capabilities of pool '%s': %s", pool.Name, err)
+		return nil
+	}
+	return c.Check(cfg)
+}
+
+// topicsInPool enqueues every Topic using a KafkaPool, so that changes to the pool are applied.
+func topicsInPool(reader client.Reader) func(ctx context.Context, obj client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		topics := &kafka_nais_io_v1.TopicList{}
+		if err := reader.List(ctx, topics); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, topic := range topics.Items {
+			if topic.Spec.Pool == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: topic.Namespace, Name: topic.Name},
+				})
+			}
+		}
+		return requests
+	}
+}
+
+// streamsInPool enqueues every Stream using a KafkaPool, so that changes to the pool are applied.
+func streamsInPool(reader client.Reader) func(ctx context.Context, obj client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		streams := &kafka_nais_io_v1.StreamList{}
+		if err := reader.List(ctx, streams); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, stream := range streams.Items {
+			if stream.Spec.Pool == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: stream.Namespace, Name: stream.Name},
+				})
+			}
+		}
+		return requests
+	}
+}
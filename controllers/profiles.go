@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nais/kafkarator/pkg/topicconfig"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/nais/liberator/pkg/hash"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// profile reads the profile selected for a topic through ProfileAnnotation.
+// Returns nil if the topic does not select a profile.
+func (r *TopicReconciler) profile(ctx context.Context, topic kafka_nais_io_v1.Topic) (*topicconfig.Profile, error) {
+	name := topic.Annotations[ProfileAnnotation]
+	if len(name) == 0 {
+		return nil, nil
+	}
+	if len(r.ProfileConfigMap.Name) == 0 || r.ProfileCache == nil {
+		return nil, fmt.Errorf("topic profiles are not enabled in this cluster")
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := r.ProfileCache.Get(ctx, r.ProfileConfigMap, configMap)
+	if err != nil {
+		return nil, fmt.Errorf("read profile ConfigMap '%s': %w", r.ProfileConfigMap, err)
+	}
+	return topicconfig.LookupProfile(configMap.Data, topic.Spec.Pool, name)
+}
+
+// hashWithProfile extends the topic synchronization hash with the profile version,
+// so that profile updates are synchronized even if the Topic resource is unchanged.
+func hashWithProfile(topicHash string, name string, profile *topicconfig.Profile) (string, error) {
+	if profile == nil {
+		return topicHash, nil
+	}
+	return hash.Hash(struct {
+		Topic   string
+		Profile string
+		Version string
+	}{
+		Topic:   topicHash,
+		Profile: name,
+		Version: profile.Version,
+	})
+}
+
+// topicsWithProfile enqueues the Topics selecting a profile of a pool whose profiles changed in the profile ConfigMap,
+// so that profile updates are applied without waiting for the next resync.
+// Every Topic selecting a profile is enqueued when the ConfigMap is created or deleted.
+func topicsWithProfile(reader client.Reader) handler.EventHandler {
+	enqueue := func(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request], changed func(pool string) bool) {
+		topics := &kafka_nais_io_v1.TopicList{}
+		if err := reader.List(ctx, topics); err != nil {
+			return
+		}
+		for _, topic := range topics.Items {
+			if len(topic.Annotations[ProfileAnnotation]) > 0 && changed(topic.Spec.Pool) {
+				queue.Add(reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: topic.Namespace, Name: topic.Name},
+				})
+			}
+		}
+	}
+	all := func(string) bool { return true }
+
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, _ event.CreateEvent, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, queue, all)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			oldData := e.ObjectOld.(*corev1.ConfigMap).Data
+			newData := e.ObjectNew.(*corev1.ConfigMap).Data
+			enqueue(ctx, queue, func(pool string) bool {
+				return oldData[pool] != newData[pool]
+			})
+		},
+		DeleteFunc: func(ctx context.Context, _ event.DeleteEvent, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, queue, all)
+		},
+	}
+}
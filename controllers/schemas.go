@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nais/kafkarator/pkg/aiven/schema"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/nais/liberator/pkg/hash"
+	corev1 "k8s.io/api/core/v1"
+	apimachinery_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// schemaSpec reads the schemas declared for a topic through SchemaConfigMapAnnotation.
+// Returns nil if the topic does not declare any schemas.
+func (r *TopicReconciler) schemaSpec(ctx context.Context, topic kafka_nais_io_v1.Topic) (*schema.Spec, error) {
+	name := topic.Annotations[SchemaConfigMapAnnotation]
+	if len(name) == 0 {
+		return nil, nil
+	}
+	if r.Aiven.Schemas == nil {
+		return nil, fmt.Errorf("schema registration is not enabled in this cluster")
+	}
+
+	// Only labelled ConfigMaps are cached, so unlabelled ones are not found.
+	configMap := &corev1.ConfigMap{}
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: topic.Namespace, Name: name}, configMap)
+	if apimachinery_errors.IsNotFound(err) || (err == nil && configMap.Labels[SchemaConfigMapLabel] != "true") {
+		return nil, fmt.Errorf("schema ConfigMap '%s' not found; it must exist in namespace '%s' with the label %s: \"true\"", name, topic.Namespace, SchemaConfigMapLabel)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schema ConfigMap '%s': %w", name, err)
+	}
+
+	spec, err := schema.FromConfigMap(topic.FullName(), configMap.Data)
+	if err != nil {
+		return nil, fmt.Errorf("schema ConfigMap '%s': %w", name, err)
+	}
+	return spec, nil
+}
+
+// topicsWithSchemas enqueues every Topic declaring schemas in a ConfigMap, so that schema changes are registered.
+func topicsWithSchemas(reader client.Reader) func(ctx context.Context, obj client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		topics := &kafka_nais_io_v1.TopicList{}
+		if err := reader.List(ctx, topics, client.InNamespace(obj.GetNamespace())); err != nil {
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, topic := range topics.Items {
+			if topic.Annotations[SchemaConfigMapAnnotation] == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: topic.Namespace, Name: topic.Name},
+				})
+			}
+		}
+		return requests
+	}
+}
+
+// hashWithSchemas extends the topic synchronization hash with the declared schemas,
+// so that schema changes are synchronized even if the Topic resource is unchanged.
+func hashWithSchemas(topicHash string, spec *schema.Spec) (string, error) {
+	if spec == nil {
+		return topicHash, nil
+	}
+	return hash.Hash(struct {
+		Topic   string
+		Schemas schema.Spec
+	}{
+		Topic:   topicHash,
+		Schemas: *spec,
+	})
+}
+
+func registeredSchemasAnnotation(registered map[string]schema.Registered) (string, error) {
+	data, err := json.Marshal(registered)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
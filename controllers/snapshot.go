@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aiven/aiven-go-client/v2"
+	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
+	"github.com/nais/kafkarator/pkg/snapshot"
+	log "github.com/sirupsen/logrus"
+)
+
+// snapshotTopics records the state of topics in Aiven, with their ACLs, before they are deleted.
+// No snapshots are recorded without a store.
+func snapshotTopics(ctx context.Context, store snapshot.Store, interfaces kafkarator_aiven.Interfaces, pool kafkarator_aiven.Pool, topics []*aiven.KafkaTopic, reason string, logger log.FieldLogger) error {
+	if store == nil || len(topics) == 0 {
+		return nil
+	}
+	acls, err := interfaces.ACLs.List(ctx, pool.Project, pool.Service)
+	if err != nil {
+		return fmt.Errorf("failed to list ACLs for snapshot: %w", err)
+	}
+	for _, existing := range topics {
+		s := snapshot.New(pool.Project, pool.Service, existing, acls, reason)
+		if err = store.Save(ctx, s); err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+		logger.Infof("Saved snapshot %s of topic %s with %d ACLs", s.Name, s.Topic, len(s.ACLs))
+	}
+	return nil
+}
@@ -5,30 +5,43 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"slices"
 	"strings"
 	"time"
 
 	"github.com/aiven/aiven-go-client/v2"
 	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
 	"github.com/nais/kafkarator/pkg/aiven/acl"
+	topic_package "github.com/nais/kafkarator/pkg/aiven/topic"
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	"github.com/nais/kafkarator/pkg/kafkapool"
 	"github.com/nais/kafkarator/pkg/metrics"
+	"github.com/nais/kafkarator/pkg/snapshot"
 	"github.com/nais/kafkarator/pkg/utils"
 	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 type StreamReconcileResult struct {
 	DeleteFinalized bool
 	Skipped         bool
 	Requeue         bool
-	Status          kafka_nais_io_v1.StreamStatus
-	Error           error
+	// Paused is true if reconciliation is paused by annotation.
+	Paused bool
+	// RequeueAfter overrides the requeue interval, i.e. to retry when a change freeze ends.
+	RequeueAfter time.Duration
+	Status       kafka_nais_io_v1.StreamStatus
+	Error        error
 }
 
 type StreamReconciler struct {
@@ -38,10 +51,23 @@ type StreamReconciler struct {
 	Projects        []string
 	RequeueInterval time.Duration
 	DryRun          bool
+	ACLConcurrency  acl.Concurrency
+	// Recorder emits events for deletions that leave resources in Aiven. No events are emitted if nil.
+	Recorder events.EventRecorder
+	// ForceRelease allows the ForceReleaseAnnotation, see TopicReconciler.
+	ForceRelease bool
+	// Snapshots records the state of topics in Aiven before they are deleted. No snapshots are recorded if nil.
+	Snapshots snapshot.Store
+	// ClusterName identifies the topics in Aiven managed by this cluster, see TopicReconciler.
+	ClusterName string
 }
 
-func (r *StreamReconciler) projectWhitelisted(project string) bool {
-	return slices.Contains(r.Projects, project)
+func (r *StreamReconciler) pools() *kafkapool.Registry {
+	return &kafkapool.Registry{
+		Reader:   r.Client,
+		Aiven:    r.Aiven,
+		Projects: r.Projects,
+	}
 }
 
 // +kubebuilder:rbac:groups=kafka.nais.io,resources=streams,verbs=get;list;watch;create;update;patch;delete
@@ -71,6 +97,7 @@ func (r *StreamReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	err := r.Get(ctx, req.NamespacedName, &stream)
 	switch {
 	case k8s_errors.IsNotFound(err):
+		reportPaused("stream", req.Namespace, req.Name, false)
 		return fail(fmt.Errorf("resource deleted from cluster; noop"), false)
 	case err != nil:
 		return fail(fmt.Errorf("unable to retrieve resource from cluster: %s", err), true)
@@ -89,6 +116,7 @@ func (r *StreamReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 	// Sync to Aiven; retry if necessary
 	result := r.Process(ctx, stream, logger)
+	reportPaused("stream", stream.Namespace, stream.Name, result.Paused)
 
 	if result.Skipped {
 		return ctrl.Result{}, nil
@@ -107,6 +135,10 @@ func (r *StreamReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		if err != nil {
 			logger.Errorf("Write resource status: %s", err)
 		}
+		if result.RequeueAfter > 0 {
+			logger.Error(result.Error)
+			return ctrl.Result{RequeueAfter: result.RequeueAfter}, nil
+		}
 		return fail(result.Error, result.Requeue)
 	}
 
@@ -145,11 +177,28 @@ func (r *StreamReconciler) Process(ctx context.Context, stream kafka_nais_io_v1.
 
 	status.FullyQualifiedTopicPrefix = stream.TopicPrefix()
 
+	// Paused streams are left alone, without any calls to Aiven.
+	if paused(&stream) {
+		logger.Info(pausedMessage)
+		if status.SynchronizationState == EventPaused {
+			return StreamReconcileResult{Skipped: true, Paused: true}
+		}
+		status.SynchronizationState = EventPaused
+		status.Message = pausedMessage
+		status.Errors = nil
+		// Synchronize again when resumed.
+		status.SynchronizationHash = ""
+		return StreamReconcileResult{Paused: true, Status: status}
+	}
+
 	fail := func(err error, state string, retry bool) StreamReconcileResult {
 		var aivenError aiven.Error
 		propagatedErr := err
-		ok := errors.As(err, &aivenError)
-		if !ok {
+		if errs := joinedErrors(err); len(errs) > 1 {
+			status.Message = fmt.Sprintf("%d operations against Aiven failed", len(errs))
+			status.Errors = statusErrors(errs)
+			propagatedErr = errors.New(strings.Join(status.Errors, "; "))
+		} else if ok := errors.As(err, &aivenError); !ok {
 			status.Message = err.Error()
 			status.Errors = []string{
 				err.Error(),
@@ -177,15 +226,35 @@ func (r *StreamReconciler) Process(ctx context.Context, stream kafka_nais_io_v1.
 		propagatedErr = utils.CheckForPossibleCredentials(propagatedErr)
 
 		return StreamReconcileResult{
-			Requeue: retry,
-			Status:  status,
-			Error:   fmt.Errorf("%s: %s", state, propagatedErr),
+			Requeue:      retry,
+			RequeueAfter: freezeRequeue(err, r.RequeueInterval),
+			Status:       status,
+			Error:        fmt.Errorf("%s: %s", state, propagatedErr),
 		}
 	}
 
+	// Released resources need neither the pool nor Aiven, which may be gone.
+	if stream.ObjectMeta.DeletionTimestamp != nil {
+		if message, ok := release(&stream, r.ForceRelease, r.Recorder, logger); ok {
+			status.Message = message
+			status.SynchronizationTime = time.Now().Format(time.RFC3339)
+			status.Errors = nil
+			return StreamReconcileResult{
+				DeleteFinalized: true,
+				Status:          status,
+			}
+		}
+	}
+
+	pools := r.pools()
+	kafkaPool, err := pools.Get(ctx, stream.Spec.Pool)
+	if err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+	}
+
 	// Process or delete?
 	if stream.ObjectMeta.DeletionTimestamp != nil {
-		return r.handleDelete(ctx, stream, logger, status, fail)
+		return r.handleDelete(ctx, stream, kafkaPool, logger, status, fail)
 	}
 
 	hash, err = stream.Hash()
@@ -200,26 +269,41 @@ func (r *StreamReconciler) Process(ctx context.Context, stream kafka_nais_io_v1.
 		}
 	}
 
-	projectName := stream.Spec.Pool
-	if !r.projectWhitelisted(projectName) {
-		return fail(fmt.Errorf("pool '%s' cannot be used in this cluster", projectName), kafka_nais_io_v1.EventFailedPrepare, false)
+	// Reject invalid deletion policies early, rather than when the stream is deleted.
+	if _, err = deletionPolicy(&stream); err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
 	}
 
-	serviceName, err := r.Aiven.NameResolver.ResolveKafkaServiceName(ctx, projectName)
+	if err = pools.Allowed(ctx, stream.Spec.Pool, stream.Namespace, kafkaPool); err != nil {
+		state, retry := poolAccessFailure("stream", stream.Namespace, stream.Spec.Pool, err)
+		return fail(err, state, retry)
+	}
+	if err = maintenanceError(kafkaPool); err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+	}
+	poolFreeze, err := activeFreeze(kafkaPool)
 	if err != nil {
 		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, false)
 	}
+
+	pool, err := pools.Resolve(ctx, stream.Spec.Pool, kafkaPool)
+	if err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, false)
+	}
+	projectName, serviceName := pool.Project, pool.Service
 	aclManager := acl.Manager{
-		AivenACLs: r.Aiven.ACLs,
-		Project:   projectName,
-		Service:   serviceName,
-		Source:    acl.StreamAdapter{Stream: &stream},
-		Logger:    logger,
-		DryRun:    r.DryRun,
+		AivenACLs:   r.Aiven.ACLs,
+		Project:     projectName,
+		Service:     serviceName,
+		Source:      acl.StreamAdapter{Stream: &stream},
+		Logger:      logger,
+		DryRun:      r.DryRun,
+		Concurrency: r.ACLConcurrency.For(projectName),
+		Freeze:      poolFreeze,
 	}
 	err = aclManager.Synchronize(ctx)
 	if err != nil {
-		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+		return fail(synchronizationFailure(err))
 	}
 
 	status.SynchronizationTime = time.Now().Format(time.RFC3339)
@@ -233,52 +317,131 @@ func (r *StreamReconciler) Process(ctx context.Context, stream kafka_nais_io_v1.
 	}
 }
 
-func (r *StreamReconciler) handleDelete(ctx context.Context, stream kafka_nais_io_v1.Stream, logger log.FieldLogger, status kafka_nais_io_v1.StreamStatus, fail func(err error, state string, retry bool) StreamReconcileResult) StreamReconcileResult {
+// ownedByCluster returns false if a topic of the stream is owned by another cluster, so that it is left alone when the
+// stream is deleted. Topics created by the stream application are not tagged, and are always deleted.
+func (r *StreamReconciler) ownedByCluster(ctx context.Context, stream kafka_nais_io_v1.Stream, projectName, serviceName, topicName string, logger log.FieldLogger) (bool, error) {
+	topicLogger := logger.WithField("topic", topicName)
+	topicManager := topic_package.Manager{
+		AivenTopics: r.Aiven.Topics,
+		Project:     projectName,
+		Service:     serviceName,
+		// The manager addresses topics by namespace and name, which make up the full topic name.
+		Topic: kafka_nais_io_v1.Topic{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: stream.Namespace,
+				Name:      strings.TrimPrefix(topicName, stream.Namespace+"."),
+			},
+		},
+		Ownership: topic_package.Ownership{
+			Cluster:  r.ClusterName,
+			Takeover: stream.Annotations[TakeoverAnnotation] == "true",
+		},
+		Logger: topicLogger,
+	}
+	err := topicManager.CheckOwnership(ctx)
+	var conflict *topic_package.OwnershipConflictError
+	if errors.As(err, &conflict) {
+		topicLogger.Warnf("Leaving topic owned by cluster '%s'", conflict.Owner)
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *StreamReconciler) handleDelete(ctx context.Context, stream kafka_nais_io_v1.Stream, kafkaPool *kafkarator_nais_io_v1alpha1.KafkaPool, logger log.FieldLogger, status kafka_nais_io_v1.StreamStatus, fail func(err error, state string, retry bool) StreamReconcileResult) StreamReconcileResult {
 	logger.Infof("Permanently deleting Aiven stream topics, ACLs and its data")
 
-	projectName := stream.Spec.Pool
-	if !r.projectWhitelisted(projectName) {
-		return fail(fmt.Errorf("pool '%s' cannot be used in this cluster", projectName), kafka_nais_io_v1.EventFailedPrepare, false)
+	policy, err := deletionPolicy(&stream)
+	if err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
 	}
 
-	serviceName, err := r.Aiven.NameResolver.ResolveKafkaServiceName(ctx, projectName)
+	pools := r.pools()
+	if err := pools.Allowed(ctx, stream.Spec.Pool, stream.Namespace, kafkaPool); err != nil {
+		state, retry := poolAccessFailure("stream", stream.Namespace, stream.Spec.Pool, err)
+		return fail(err, state, retry)
+	}
+	if err := maintenanceError(kafkaPool); err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+	}
+	poolFreeze, err := activeFreeze(kafkaPool)
 	if err != nil {
 		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, false)
 	}
 
-	aclManager := acl.Manager{
-		AivenACLs: r.Aiven.ACLs,
-		Project:   projectName,
-		Service:   serviceName,
-		Source:    acl.StreamAdapter{Stream: &stream, Delete: true},
-		Logger:    logger,
-	}
-	err = aclManager.Synchronize(ctx)
+	pool, err := pools.Resolve(ctx, stream.Spec.Pool, kafkaPool)
 	if err != nil {
-		return fail(fmt.Errorf("failed to delete ACLs %s on Aiven: %s", stream.ACL(), err), kafka_nais_io_v1.EventFailedSynchronization, true)
+		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, false)
 	}
-	status.Message = "Deleted Stream ACL"
+	projectName, serviceName := pool.Project, pool.Service
 
-	logger.Infof("Permanently deleting Aiven stream and its data")
 	topics, err := r.Aiven.Topics.List(ctx, projectName, serviceName)
 	if err != nil {
 		return fail(fmt.Errorf("failed to list topics on Aiven: %s", err), kafka_nais_io_v1.EventFailedSynchronization, true)
 	}
+	var streamTopics []string
 	for _, topic := range topics {
-		if strings.HasPrefix(topic.TopicName, stream.TopicPrefix()) {
-			err = metrics.ObserveAivenLatency("Topic_Delete", projectName, func() error {
-				if r.DryRun {
-					r.Logger.Infof("DRY RUN: Would delete Topic: %v", topic.TopicName)
-					return nil
-				}
-				return r.Aiven.Topics.Delete(ctx, projectName, serviceName, topic.TopicName)
-			})
-			if err != nil {
-				return fail(fmt.Errorf("failed to delete topic '%s' on Aiven: %s", topic.TopicName, err), kafka_nais_io_v1.EventFailedSynchronization, true)
+		if !strings.HasPrefix(topic.TopicName, stream.TopicPrefix()) {
+			continue
+		}
+		owned, err := r.ownedByCluster(ctx, stream, projectName, serviceName, topic.TopicName, logger)
+		if err != nil {
+			return fail(fmt.Errorf("failed to get topic '%s' from Aiven: %w", topic.TopicName, err), kafka_nais_io_v1.EventFailedSynchronization, true)
+		}
+		if owned {
+			streamTopics = append(streamTopics, topic.TopicName)
+		}
+	}
+
+	var changes []string
+	if policy != DeletionPolicyRetainACLs {
+		changes = append(changes, fmt.Sprintf("delete ACLs of stream %s", stream.TopicWildcard()))
+	}
+	for _, topicName := range streamTopics {
+		changes = append(changes, fmt.Sprintf("delete topic %s", topicName))
+	}
+	if err = poolFreeze.Check(changes...); err != nil {
+		return fail(synchronizationFailure(err))
+	}
+	if err = r.snapshot(ctx, stream, pool, streamTopics, logger); err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+	}
+
+	if policy == DeletionPolicyRetainACLs {
+		logger.Warn("Keeping ACLs of stream by the retain-acls deletion policy")
+		emit(r.Recorder, &stream, corev1.EventTypeNormal, EventReasonRetainedACLs, "Delete", "ACLs kept in Aiven by the retain-acls deletion policy")
+	} else {
+		aclManager := acl.Manager{
+			AivenACLs:   r.Aiven.ACLs,
+			Project:     projectName,
+			Service:     serviceName,
+			Source:      acl.StreamAdapter{Stream: &stream, Delete: true},
+			Logger:      logger,
+			Concurrency: r.ACLConcurrency.For(projectName),
+		}
+		err = aclManager.Synchronize(ctx)
+		if err != nil {
+			return fail(fmt.Errorf("failed to delete ACLs %s on Aiven: %w", stream.ACL(), err), kafka_nais_io_v1.EventFailedSynchronization, true)
+		}
+		status.Message = "Deleted Stream ACL"
+	}
+
+	logger.Infof("Permanently deleting Aiven stream and its data")
+	for _, topicName := range streamTopics {
+		err = metrics.ObserveAivenLatency("Topic_Delete", projectName, func() error {
+			if r.DryRun {
+				r.Logger.Infof("DRY RUN: Would delete Topic: %v", topicName)
+				return nil
 			}
+			return r.Aiven.Topics.Delete(ctx, projectName, serviceName, topicName)
+		})
+		if err != nil {
+			return fail(fmt.Errorf("failed to delete topic '%s' on Aiven: %s", topicName, err), kafka_nais_io_v1.EventFailedSynchronization, true)
 		}
 	}
 	status.Message = "Stream, ACLs and data permanently deleted"
+	if policy == DeletionPolicyRetainACLs {
+		status.Message = "Stream and data permanently deleted, ACLs kept"
+	}
 
 	logger.Info(status.Message)
 	status.SynchronizationTime = time.Now().Format(time.RFC3339)
@@ -289,8 +452,28 @@ func (r *StreamReconciler) handleDelete(ctx context.Context, stream kafka_nais_i
 	}
 }
 
+// snapshot records the state of the stream topics in Aiven before they are deleted.
+func (r *StreamReconciler) snapshot(ctx context.Context, stream kafka_nais_io_v1.Stream, pool kafkarator_aiven.Pool, topicNames []string, logger log.FieldLogger) error {
+	if r.Snapshots == nil {
+		return nil
+	}
+	topics := make([]*aiven.KafkaTopic, 0, len(topicNames))
+	for _, topicName := range topicNames {
+		existing, err := r.Aiven.Topics.Get(ctx, pool.Project, pool.Service, topicName)
+		if err != nil {
+			return fmt.Errorf("failed to get topic '%s' from Aiven: %w", topicName, err)
+		}
+		topics = append(topics, existing)
+	}
+	reason := fmt.Sprintf("Stream %s/%s deleted", stream.Namespace, stream.Name)
+	return snapshotTopics(ctx, r.Snapshots, r.Aiven, pool, topics, reason, logger)
+}
+
 func (r *StreamReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kafka_nais_io_v1.Stream{}).
+		// Only spec changes fan out; the metadata collector updates the status of every pool on each report.
+		Watches(&kafkarator_nais_io_v1alpha1.KafkaPool{}, handler.EnqueueRequestsFromMapFunc(streamsInPool(r.Client)),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Complete(r)
 }
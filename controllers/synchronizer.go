@@ -2,60 +2,212 @@ package controllers
 
 import (
 	"context"
+	"errors"
 
 	"github.com/nais/kafkarator/pkg/aiven"
 	"github.com/nais/kafkarator/pkg/aiven/acl"
+	"github.com/nais/kafkarator/pkg/aiven/schema"
 	"github.com/nais/kafkarator/pkg/aiven/topic"
+	"github.com/nais/kafkarator/pkg/freeze"
+	"github.com/nais/kafkarator/pkg/utils"
 	"github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/nais/liberator/pkg/hash"
 	log "github.com/sirupsen/logrus"
 )
 
 type Synchronizer struct {
-	ACLs   acl.Manager
-	Topics topic.Manager
-	Logger *log.Entry
+	ACLs               acl.Manager
+	SchemaRegistryACLs *acl.SchemaRegistryManager
+	Topics             topic.Manager
+	Schemas            *schema.Manager
+	SchemaSpec         *schema.Spec
+	Logger             *log.Entry
+	// Adopt allows synchronizing topics created in Aiven outside Kafkarator.
+	Adopt bool
+
+	// Adopted is populated by Synchronize when a topic is adopted.
+	Adopted *adoptedTopic
+	// RegisteredSchemas is populated by Synchronize when the topic declares schemas.
+	RegisteredSchemas map[string]schema.Registered
+	// Phases holds the outcome of each phase of the previous synchronization, and is updated by Synchronize.
+	Phases phases
 }
 
-func NewSynchronizer(ctx context.Context, a kafkarator_aiven.Interfaces, t kafka_nais_io_v1.Topic, logger *log.Entry, dryRun bool) (*Synchronizer, error) {
-	projectName := t.Spec.Pool
-	serviceName, err := a.NameResolver.ResolveKafkaServiceName(ctx, projectName)
-	if err != nil {
-		return nil, err
-	}
+func NewSynchronizer(a kafkarator_aiven.Interfaces, pool kafkarator_aiven.Pool, t kafka_nais_io_v1.Topic, logger *log.Entry, dryRun bool, aclConcurrency int, schemaSpec *schema.Spec, extraConfig topic.ExtraConfig, ownership topic.Ownership) *Synchronizer {
+	projectName, serviceName := pool.Project, pool.Service
 
-	return &Synchronizer{
+	synchronizer := &Synchronizer{
 		Logger: logger,
 		Topics: topic.Manager{
 			AivenTopics: a.Topics,
 			Project:     projectName,
 			Service:     serviceName,
 			Topic:       t,
+			ExtraConfig: extraConfig,
+			Ownership:   ownership,
 			Logger:      logger,
 			DryRun:      dryRun,
 		},
 		ACLs: acl.Manager{
-			AivenACLs: a.ACLs,
-			Project:   projectName,
-			Service:   serviceName,
-			Source:    acl.TopicAdapter{Topic: &t},
-			Logger:    logger,
-			DryRun:    dryRun,
+			AivenACLs:   a.ACLs,
+			Project:     projectName,
+			Service:     serviceName,
+			Source:      acl.TopicAdapter{Topic: &t},
+			Logger:      logger,
+			DryRun:      dryRun,
+			Concurrency: aclConcurrency,
 		},
-	}, nil
+	}
+
+	if a.SchemaRegistryACLs != nil {
+		synchronizer.SchemaRegistryACLs = &acl.SchemaRegistryManager{
+			AivenSchemaRegistryACLs: a.SchemaRegistryACLs,
+			Project:                 projectName,
+			Service:                 serviceName,
+			Source:                  acl.TopicAdapter{Topic: &t},
+			Logger:                  logger,
+			DryRun:                  dryRun,
+			Concurrency:             aclConcurrency,
+		}
+	}
+
+	if schemaSpec != nil {
+		synchronizer.SchemaSpec = schemaSpec
+		synchronizer.Schemas = &schema.Manager{
+			AivenSchemas: a.Schemas,
+			Project:      projectName,
+			Service:      serviceName,
+			Logger:       logger,
+			DryRun:       dryRun,
+		}
+	}
+
+	return synchronizer
+}
+
+// Freeze defers changes to topics and ACLs while the pool is frozen.
+func (c *Synchronizer) Freeze(f *freeze.Freeze) {
+	c.Topics.Freeze = f
+	c.ACLs.Freeze = f
+	if c.SchemaRegistryACLs != nil {
+		c.SchemaRegistryACLs.Freeze = f
+	}
 }
 
+// Synchronize runs the synchronization phases in order, and records their outcome in Phases.
+// When retrying a synchronization that did not complete, phases that completed with the same inputs are skipped.
+// Changes deferred by a freeze are collected from every phase making changes, so that all of them are reported.
 func (c *Synchronizer) Synchronize(ctx context.Context) error {
-	c.Logger.Infof("Synchronizing access control lists")
-	err := c.ACLs.Synchronize(ctx)
+	aclHash, err := hash.Hash(struct {
+		Topic string
+		ACLs  kafka_nais_io_v1.TopicACLs
+	}{
+		Topic: c.ACLs.Source.TopicName(),
+		ACLs:  c.ACLs.Source.ACLs(),
+	})
 	if err != nil {
 		return err
 	}
+	specHash := c.Topics.Ownership.Hash
 
+	retry := c.Phases.incomplete()
+	if !retry {
+		c.Phases = nil
+	}
+
+	var deferred []*freeze.DeferredError
+	for _, phase := range []struct {
+		name string
+		hash string
+		run  func(ctx context.Context) error
+	}{
+		{PhaseValidate, specHash, c.validate},
+		// The topic is created before its ACLs, so that no ACLs are left for a topic that failed to be created.
+		{PhaseTopic, specHash, c.synchronizeTopic},
+		{PhaseACLs, aclHash, c.synchronizeACLs},
+		{PhaseVerify, specHash, c.Topics.Verify},
+	} {
+		if phase.name == PhaseVerify && len(deferred) > 0 {
+			break
+		}
+		if phase.name != PhaseValidate && retry && c.Phases.completed(phase.name, phase.hash) {
+			c.Logger.Infof("Skipping phase %s, completed in an earlier attempt", phase.name)
+			continue
+		}
+
+		err = phase.run(ctx)
+		var d *freeze.DeferredError
+		switch {
+		case errors.As(err, &d):
+			c.Phases.set(phase.name, phase.hash, PhaseDeferred, err)
+			deferred = append(deferred, d)
+		case err != nil:
+			c.Phases.set(phase.name, phase.hash, PhaseFailed, utils.CheckForPossibleCredentials(err))
+			return err
+		default:
+			c.Phases.set(phase.name, phase.hash, PhaseComplete, nil)
+		}
+	}
+
+	if len(deferred) > 0 {
+		return freeze.Join(deferred...)
+	}
+	return nil
+}
+
+func (c *Synchronizer) validate(ctx context.Context) error {
+	// Access to topics owned by another cluster is left alone as well.
+	err := c.Topics.CheckOwnership(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkAdoption(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Changes that delete data are refused before anything is changed.
+	return c.Topics.CheckDataLoss(ctx)
+}
+
+func (c *Synchronizer) synchronizeACLs(ctx context.Context) error {
+	c.Logger.Infof("Synchronizing access control lists")
+	aclErr := c.ACLs.Synchronize(ctx)
+	var deferred *freeze.DeferredError
+	if aclErr != nil && !errors.As(aclErr, &deferred) {
+		return aclErr
+	}
+
+	if c.SchemaRegistryACLs != nil {
+		c.Logger.Infof("Synchronizing schema registry access control lists")
+		err := c.SchemaRegistryACLs.Synchronize(ctx)
+		var schemaDeferred *freeze.DeferredError
+		switch {
+		case errors.As(err, &schemaDeferred):
+			return freeze.Join(deferred, schemaDeferred)
+		case err != nil:
+			return err
+		}
+	}
+
+	return aclErr
+}
+
+func (c *Synchronizer) synchronizeTopic(ctx context.Context) error {
 	c.Logger.Infof("Synchronizing topic")
-	err = c.Topics.Synchronize(ctx)
+	err := c.Topics.Synchronize(ctx)
 	if err != nil {
 		return err
 	}
 
+	if c.Schemas != nil {
+		c.Logger.Infof("Registering schemas")
+		c.RegisteredSchemas, err = c.Schemas.Synchronize(ctx, *c.SchemaSpec)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
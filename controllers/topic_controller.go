@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"slices"
+	"strings"
 	"time"
 
 	"github.com/nais/kafkarator/pkg/utils"
@@ -13,17 +13,33 @@ import (
 	"github.com/aiven/aiven-go-client/v2"
 	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
 	"github.com/nais/kafkarator/pkg/aiven/acl"
+	topic_package "github.com/nais/kafkarator/pkg/aiven/topic"
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	"github.com/nais/kafkarator/pkg/deletion"
+	"github.com/nais/kafkarator/pkg/kafkapool"
 	"github.com/nais/kafkarator/pkg/metrics"
+	"github.com/nais/kafkarator/pkg/policy"
+	"github.com/nais/kafkarator/pkg/snapshot"
+	"github.com/nais/kafkarator/pkg/topicconfig"
 	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	corev1 "k8s.io/api/core/v1"
 	apimachinery_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
 )
 
 const (
@@ -34,8 +50,14 @@ type TopicReconcileResult struct {
 	DeleteFinalized bool
 	Skipped         bool
 	Requeue         bool
-	Status          kafka_nais_io_v1.TopicStatus
-	Error           error
+	// Paused is true if reconciliation is paused by annotation.
+	Paused bool
+	// RequeueAfter overrides the requeue interval, i.e. to retry when a change freeze ends.
+	RequeueAfter time.Duration
+	Status       kafka_nais_io_v1.TopicStatus
+	// Annotations are written to the Topic resource together with the status. Empty values remove the annotation.
+	Annotations map[string]string
+	Error       error
 }
 
 type TopicReconciler struct {
@@ -45,10 +67,36 @@ type TopicReconciler struct {
 	Projects        []string
 	RequeueInterval time.Duration
 	DryRun          bool
+	ACLConcurrency  acl.Concurrency
+	// Policy for topic configuration. Topics are not checked against any policy if nil.
+	Policy *policy.Store
+	// ClusterName is written as a tag on topics in Aiven, to tell which cluster manages them.
+	ClusterName string
+	// ProfileConfigMap holds the topic configuration profiles of each pool. Profiles are not available if unset.
+	ProfileConfigMap types.NamespacedName
+	// ProfileCache caches and watches only the profile ConfigMap, as the manager only caches schema ConfigMaps.
+	ProfileCache cache.Cache
+	// Recorder emits events for deletions that leave resources in Aiven. No events are emitted if nil.
+	Recorder events.EventRecorder
+	// ForceRelease allows the ForceReleaseAnnotation. Only enable it when an admission policy restricts the
+	// annotation to administrators, such as the Kyverno policy of the chart.
+	ForceRelease bool
+	// DeletionGracePeriod delays the deletion of topic data after a Topic resource is deleted. Data is deleted at once if zero.
+	DeletionGracePeriod time.Duration
+	// Reaper deletes the data of deleted topics when their grace period has passed.
+	Reaper *deletion.Reaper
+	// VerifyTimeout is how long to wait for a synchronized topic to become active in Aiven, before retrying later.
+	VerifyTimeout time.Duration
+	// Snapshots records the state of topics in Aiven before they are deleted. No snapshots are recorded if nil.
+	Snapshots snapshot.Store
 }
 
-func (r *TopicReconciler) projectWhitelisted(project string) bool {
-	return slices.Contains(r.Projects, project)
+func (r *TopicReconciler) pools() *kafkapool.Registry {
+	return &kafkapool.Registry{
+		Reader:   r.Client,
+		Aiven:    r.Aiven,
+		Projects: r.Projects,
+	}
 }
 
 // Process changes in Aiven and return a topic processing status
@@ -63,11 +111,28 @@ func (r *TopicReconciler) Process(ctx context.Context, topic kafka_nais_io_v1.To
 
 	status.FullyQualifiedName = topic.FullName()
 
+	// Paused topics are left alone, without any calls to Aiven.
+	if paused(&topic) {
+		logger.Info(pausedMessage)
+		if status.SynchronizationState == EventPaused {
+			return TopicReconcileResult{Skipped: true, Paused: true}
+		}
+		status.SynchronizationState = EventPaused
+		status.Message = pausedMessage
+		status.Errors = nil
+		// Synchronize again when resumed.
+		status.SynchronizationHash = ""
+		return TopicReconcileResult{Paused: true, Status: status}
+	}
+
 	fail := func(err error, state string, retry bool) TopicReconcileResult {
 		var aivenError aiven.Error
 		propagatedErr := err
-		ok := errors.As(err, &aivenError)
-		if !ok {
+		if errs := joinedErrors(err); len(errs) > 1 {
+			status.Message = fmt.Sprintf("%d operations against Aiven failed", len(errs))
+			status.Errors = statusErrors(errs)
+			propagatedErr = errors.New(strings.Join(status.Errors, "; "))
+		} else if ok := errors.As(err, &aivenError); !ok {
 			status.Message = err.Error()
 			status.Errors = []string{
 				err.Error(),
@@ -98,38 +163,118 @@ func (r *TopicReconciler) Process(ctx context.Context, topic kafka_nais_io_v1.To
 		propagatedErr = utils.CheckForPossibleCredentials(propagatedErr)
 
 		return TopicReconcileResult{
-			Requeue: retry,
-			Status:  status,
-			Error:   fmt.Errorf("%s: %s", state, propagatedErr),
+			Requeue:      retry,
+			RequeueAfter: freezeRequeue(err, r.RequeueInterval),
+			Status:       status,
+			Error:        fmt.Errorf("%s: %s", state, propagatedErr),
 		}
 	}
 
+	// Released resources need neither the pool nor Aiven, which may be gone.
+	if topic.ObjectMeta.DeletionTimestamp != nil {
+		if message, ok := release(&topic, r.ForceRelease, r.Recorder, logger); ok {
+			status.Message = message
+			status.SynchronizationTime = time.Now().Format(time.RFC3339)
+			status.Errors = nil
+			return TopicReconcileResult{
+				DeleteFinalized: true,
+				Status:          status,
+			}
+		}
+	}
+
+	pools := r.pools()
+	kafkaPool, err := pools.Get(ctx, topic.Spec.Pool)
+	if err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+	}
+
 	// Process or delete?
-	projectName := topic.Spec.Pool
-	serviceName, err := r.Aiven.NameResolver.ResolveKafkaServiceName(ctx, projectName)
+	pool, err := pools.Resolve(ctx, topic.Spec.Pool, kafkaPool)
 	if err != nil {
 		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, false)
 	}
+	projectName, serviceName := pool.Project, pool.Service
 
 	if topic.ObjectMeta.DeletionTimestamp != nil {
-		logger.Info("Deleting ACls for topic")
-		strippedTopic := topic.DeepCopy()
-		strippedTopic.Spec.ACL = nil
-		aclManager := acl.Manager{
-			AivenACLs: r.Aiven.ACLs,
-			Project:   projectName,
-			Service:   serviceName,
-			Source:    acl.TopicAdapter{Topic: strippedTopic},
-			Logger:    logger,
-			DryRun:    r.DryRun,
+		policy, err := deletionPolicy(&topic)
+		if err != nil {
+			return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
 		}
-		err = aclManager.Synchronize(ctx)
+		if err = maintenanceError(kafkaPool); err != nil {
+			return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+		}
+		poolFreeze, err := activeFreeze(kafkaPool)
 		if err != nil {
-			return fail(fmt.Errorf("failed to delete ACLs on Aiven: %s", err), kafka_nais_io_v1.EventFailedSynchronization, true)
+			return fail(err, kafka_nais_io_v1.EventFailedSynchronization, false)
+		}
+
+		topicManager := topic_package.Manager{
+			AivenTopics: r.Aiven.Topics,
+			Project:     projectName,
+			Service:     serviceName,
+			Topic:       topic,
+			Ownership: topic_package.Ownership{
+				Cluster:  r.ClusterName,
+				Takeover: takeover(topic),
+			},
+			Logger: logger,
+			DryRun: r.DryRun,
+		}
+		if err = topicManager.CheckOwnership(ctx); err != nil {
+			return fail(synchronizationFailure(err))
+		}
+
+		deleteData := topic.RemoveDataWhenDeleted() || policy == DeletionPolicyRetainACLs
+		change := fmt.Sprintf("delete ACLs and data of topic %s", topic.FullName())
+		switch {
+		case policy == DeletionPolicyRetainACLs:
+			change = fmt.Sprintf("delete data of topic %s", topic.FullName())
+		case !deleteData:
+			change = fmt.Sprintf("delete ACLs of topic %s", topic.FullName())
+		}
+		if err = poolFreeze.Check(change); err != nil {
+			return fail(synchronizationFailure(err))
+		}
+
+		existing, err := topicManager.Existing(ctx)
+		if err != nil {
+			return fail(fmt.Errorf("failed to get topic from Aiven: %w", err), kafka_nais_io_v1.EventFailedSynchronization, true)
+		}
+		if existing != nil {
+			reason := fmt.Sprintf("Topic %s/%s deleted", topic.Namespace, topic.Name)
+			if err = snapshotTopics(ctx, r.Snapshots, r.Aiven, pool, []*aiven.KafkaTopic{existing}, reason, logger); err != nil {
+				return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+			}
 		}
-		status.Message = "Topic and ACLs deleted, data kept"
 
-		if topic.RemoveDataWhenDeleted() {
+		if policy == DeletionPolicyRetainACLs {
+			logger.Warn("Keeping ACLs of topic by the retain-acls deletion policy")
+			emit(r.Recorder, &topic, corev1.EventTypeNormal, EventReasonRetainedACLs, "Delete", "ACLs kept in Aiven by the retain-acls deletion policy")
+		} else {
+			if err = r.deleteACLs(ctx, topic, projectName, serviceName, logger); err != nil {
+				return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+			}
+			status.Message = "Topic and ACLs deleted, data kept"
+		}
+
+		if deleteData && r.DeletionGracePeriod > 0 {
+			deleteAfter := time.Now().Add(r.DeletionGracePeriod)
+			logger.Infof("Scheduling deletion of topic data at %s", deleteAfter.Format(time.RFC3339))
+			exists, err := topicManager.ScheduleDeletion(ctx, deleteAfter)
+			if err != nil {
+				return fail(fmt.Errorf("failed to schedule deletion of topic on Aiven: %w", err), kafka_nais_io_v1.EventFailedSynchronization, true)
+			}
+			if exists {
+				r.Reaper.Track(topic.Spec.Pool, topic.FullName())
+				status.Message = fmt.Sprintf("ACLs deleted, data scheduled for deletion after %s", r.DeletionGracePeriod)
+				if policy == DeletionPolicyRetainACLs {
+					status.Message = fmt.Sprintf("ACLs kept, data scheduled for deletion after %s", r.DeletionGracePeriod)
+				}
+			} else {
+				logger.Info("Topic already removed from Aiven")
+			}
+		} else if deleteData {
 			logger.Info("Permanently deleting Aiven topic and its data")
 			err = metrics.ObserveAivenLatency("Topic_Delete", projectName, func() error {
 				if r.DryRun {
@@ -146,6 +291,9 @@ func (r *TopicReconciler) Process(ctx context.Context, topic kafka_nais_io_v1.To
 				}
 			}
 			status.Message = "Topic, ACLs and data permanently deleted"
+			if policy == DeletionPolicyRetainACLs {
+				status.Message = "Topic and data permanently deleted, ACLs kept"
+			}
 		}
 
 		logger.Info(status.Message)
@@ -160,9 +308,45 @@ func (r *TopicReconciler) Process(ctx context.Context, topic kafka_nais_io_v1.To
 	if topic.Spec.Config == nil {
 		topic.Spec.Config = &kafka_nais_io_v1.Config{}
 	}
+	profile, err := r.profile(ctx, topic)
+	if err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
+	}
+	topicconfig.ApplyProfile(topic.Spec.Config, profile)
+	if kafkaPool != nil {
+		kafkaPool.ApplyTopicDefaults(topic.Spec.Config)
+	}
 	topic.Spec.Config.ApplyDefaults()
 
+	schemaSpec, err := r.schemaSpec(ctx, topic)
+	if err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
+	}
+
+	extra, err := extraConfig(topic)
+	if err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
+	}
+
+	// Reject invalid deletion policies early, rather than when the topic is deleted.
+	if _, err = deletionPolicy(&topic); err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
+	}
+
 	hash, err = topic.Hash()
+	if err == nil {
+		hash, err = hashWithSchemas(hash, schemaSpec)
+	}
+	if err == nil {
+		hash, err = hashWithProfile(hash, topic.Annotations[ProfileAnnotation], profile)
+	}
+	if err == nil {
+		hash, err = hashWithExtraConfig(hash, extra)
+	}
+	violations := r.Policy.Evaluate(topic.Spec.Pool, topic.Spec.Config)
+	if err == nil {
+		hash, err = hashWithPolicy(hash, violations)
+	}
 	if err != nil {
 		return fail(fmt.Errorf("unable to calculate synchronization hash"), kafka_nais_io_v1.EventFailedPrepare, false)
 	}
@@ -174,17 +358,66 @@ func (r *TopicReconciler) Process(ctx context.Context, topic kafka_nais_io_v1.To
 		}
 	}
 
-	if !r.projectWhitelisted(projectName) {
-		return fail(fmt.Errorf("pool '%s' cannot be used in this cluster", projectName), kafka_nais_io_v1.EventFailedPrepare, false)
+	if err = pools.Allowed(ctx, topic.Spec.Pool, topic.Namespace, kafkaPool); err != nil {
+		state, retry := poolAccessFailure("topic", topic.Namespace, topic.Spec.Pool, err)
+		return fail(err, state, retry)
 	}
-
-	synchronizer, err := NewSynchronizer(ctx, r.Aiven, topic, logger, r.DryRun)
+	if kafkaPool != nil {
+		if err = kafkaPool.CheckLimits(topic.Spec.Config); err != nil {
+			return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
+		}
+		if quota := kafkaPool.QuotaFor(topic.Namespace); quota != nil {
+			usage, err := pools.Usage(ctx, kafkaPool, &topic)
+			if err != nil {
+				return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+			}
+			reportQuotaUsage(topic.Namespace, topic.Spec.Pool, usage, quota)
+			// Retry, as the quota may be freed by changes to other topics.
+			if err = usage.Check(quota); err != nil {
+				return fail(fmt.Errorf("quota in pool '%s' exceeded: %w", topic.Spec.Pool, err), kafka_nais_io_v1.EventFailedPrepare, true)
+			}
+		}
+	}
+	if err = checkCapabilities(ctx, r.Aiven.Capabilities, pool, topic.Spec.Config, logger); err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
+	}
+	policyWarnings, err := checkPolicy(topic.Spec.Pool, violations)
+	if err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedPrepare, false)
+	}
+	if err = maintenanceError(kafkaPool); err != nil {
+		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+	}
+	poolFreeze, err := activeFreeze(kafkaPool)
 	if err != nil {
 		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, false)
 	}
+
+	synchronizer := NewSynchronizer(r.Aiven, pool, topic, logger, r.DryRun, r.ACLConcurrency.For(projectName), schemaSpec, extra, topic_package.Ownership{
+		Cluster:  r.ClusterName,
+		Hash:     hash,
+		Takeover: takeover(topic),
+	})
+	synchronizer.Adopt = adopt(topic)
+	synchronizer.Topics.AcknowledgedDataLoss = topic.Annotations[AcknowledgeDataLossAnnotation]
+	synchronizer.Freeze(poolFreeze)
+	synchronizer.Topics.VerifyTimeout = r.VerifyTimeout
+	synchronizer.Phases, err = parsePhases(topic)
+	if err != nil {
+		logger.Warnf("Synchronizing all phases: %s", err)
+	}
 	err = synchronizer.Synchronize(ctx)
+	var dataLoss *topic_package.DataLossError
+	if errors.As(err, &dataLoss) {
+		emit(r.Recorder, &topic, corev1.EventTypeWarning, EventDataLossNotAcknowledged, "Synchronize", err.Error())
+	}
 	if err != nil {
-		return fail(err, kafka_nais_io_v1.EventFailedSynchronization, true)
+		result := fail(synchronizationFailure(err))
+		if phase, ok := synchronizer.Phases.failed(); ok {
+			result.Status.Message = fmt.Sprintf("Phase %s failed: %s", phase.Phase, result.Status.Message)
+		}
+		result.Annotations = r.synchronizerAnnotations(topic, synchronizer, logger)
+		return result
 	}
 
 	status.SynchronizationTime = time.Now().Format(time.RFC3339)
@@ -193,10 +426,92 @@ func (r *TopicReconciler) Process(ctx context.Context, topic kafka_nais_io_v1.To
 	status.Message = "Topic configuration synchronized to Kafka pool"
 	status.Errors = nil
 	status.LatestAivenSyncFailure = ""
+	if len(policyWarnings) > 0 {
+		status.Message = fmt.Sprintf("%s, with %d policy warnings", status.Message, len(policyWarnings))
+	}
+
+	result := TopicReconcileResult{
+		Status:      status,
+		Annotations: r.synchronizerAnnotations(topic, synchronizer, logger),
+	}
+	result.Annotations[PolicyWarningsAnnotation] = strings.Join(policyWarnings, "\n")
+
+	// The topic is synchronized even if its state in Aiven cannot be summarized; the summary is refreshed next time.
+	observed, err := synchronizer.Observe(ctx)
+	if err == nil {
+		result.Annotations[AivenStateAnnotation], err = aivenStateAnnotation(observed)
+	}
+	if err != nil {
+		logger.Warnf("Unable to summarize the topic in Aiven: %s", err)
+	}
 
-	return TopicReconcileResult{
-		Status: status,
+	return result
+}
+
+// synchronizerAnnotations returns the annotations recording the outcome of a synchronization, also when it failed,
+// so that completed phases are not repeated.
+func (r *TopicReconciler) synchronizerAnnotations(topic kafka_nais_io_v1.Topic, synchronizer *Synchronizer, logger *log.Entry) map[string]string {
+	annotations := make(map[string]string)
+	phases, err := synchronizer.Phases.annotation()
+	if err != nil {
+		logger.Errorf("Unable to encode synchronization phases: %s", err)
+	} else {
+		annotations[SynchronizationPhasesAnnotation] = phases
+	}
+	if synchronizer.RegisteredSchemas != nil {
+		registered, err := registeredSchemasAnnotation(synchronizer.RegisteredSchemas)
+		if err != nil {
+			logger.Errorf("Unable to encode registered schemas: %s", err)
+		} else {
+			annotations[RegisteredSchemasAnnotation] = registered
+		}
+	}
+	// The topic before adoption is kept when retrying, as the ACL phase may have changed it since.
+	if synchronizer.Adopted != nil && len(topic.Annotations[AdoptedTopicAnnotation]) == 0 {
+		adopted, err := adoptedTopicAnnotation(synchronizer.Adopted)
+		if err != nil {
+			logger.Errorf("Unable to encode adopted topic: %s", err)
+		} else {
+			annotations[AdoptedTopicAnnotation] = adopted
+		}
+	}
+	return annotations
+}
+
+// deleteACLs deletes the ACLs and schema registry ACLs of a deleted topic from Aiven.
+func (r *TopicReconciler) deleteACLs(ctx context.Context, topic kafka_nais_io_v1.Topic, projectName, serviceName string, logger *log.Entry) error {
+	logger.Info("Deleting ACls for topic")
+	strippedTopic := topic.DeepCopy()
+	strippedTopic.Spec.ACL = nil
+	aclManager := acl.Manager{
+		AivenACLs:   r.Aiven.ACLs,
+		Project:     projectName,
+		Service:     serviceName,
+		Source:      acl.TopicAdapter{Topic: strippedTopic},
+		Logger:      logger,
+		DryRun:      r.DryRun,
+		Concurrency: r.ACLConcurrency.For(projectName),
+	}
+	err := aclManager.Synchronize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete ACLs on Aiven: %w", err)
 	}
+	if r.Aiven.SchemaRegistryACLs != nil {
+		schemaRegistryManager := acl.SchemaRegistryManager{
+			AivenSchemaRegistryACLs: r.Aiven.SchemaRegistryACLs,
+			Project:                 projectName,
+			Service:                 serviceName,
+			Source:                  acl.TopicAdapter{Topic: strippedTopic},
+			Logger:                  logger,
+			DryRun:                  r.DryRun,
+			Concurrency:             r.ACLConcurrency.For(projectName),
+		}
+		err = schemaRegistryManager.Synchronize(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to delete schema registry ACLs on Aiven: %w", err)
+		}
+	}
+	return nil
 }
 
 // +kubebuilder:rbac:groups=kafka.nais.io,resources=topics,verbs=get;list;watch;create;update;patch;delete
@@ -229,6 +544,7 @@ func (r *TopicReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	err := r.Get(ctx, req.NamespacedName, &topic)
 	switch {
 	case apimachinery_errors.IsNotFound(err):
+		reportPaused("topic", req.Namespace, req.Name, false)
 		return fail(fmt.Errorf("resource deleted from cluster; noop"), false)
 	case err != nil:
 		return fail(fmt.Errorf("unable to retrieve resource from cluster: %s", err), true)
@@ -251,6 +567,7 @@ func (r *TopicReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 	// Sync to Aiven; retry if necessary
 	result := r.Process(ctx, topic, logger)
+	reportPaused("topic", topic.Namespace, topic.Name, result.Paused)
 
 	if result.Skipped {
 		return ctrl.Result{}, nil
@@ -263,12 +580,24 @@ func (r *TopicReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		}).Inc()
 	}()
 
+	for key, value := range result.Annotations {
+		if len(value) == 0 {
+			delete(topic.Annotations, key)
+			continue
+		}
+		metav1.SetMetaDataAnnotation(&topic.ObjectMeta, key, value)
+	}
+
 	if result.Error != nil {
 		topic.Status = &result.Status
 		err = r.Update(ctx, &topic)
 		if err != nil {
 			logger.Errorf("Write resource status: %s", err)
 		}
+		if result.RequeueAfter > 0 {
+			logger.Error(result.Error)
+			return ctrl.Result{RequeueAfter: result.RequeueAfter}, nil
+		}
 		return fail(result.Error, result.Requeue)
 	}
 
@@ -294,10 +623,21 @@ func (r *TopicReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 }
 
 func (r *TopicReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr)
+	if r.ProfileCache != nil {
+		b = b.WatchesRawSource(source.Kind(r.ProfileCache, client.Object(&corev1.ConfigMap{}), topicsWithProfile(r.Client)))
+	}
+	return b.
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: 10,
 		}).
 		For(&kafka_nais_io_v1.Topic{}).
+		// Only spec changes fan out; the metadata collector updates the status of every pool on each report.
+		Watches(&kafkarator_nais_io_v1alpha1.KafkaPool{}, handler.EnqueueRequestsFromMapFunc(topicsInPool(r.Client)),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(topicsWithSchemas(r.Client)),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetLabels()[SchemaConfigMapLabel] == "true"
+			}))).
 		Complete(r)
 }
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/nais/kafkarator/pkg/utils"
 	"github.com/nais/liberator/pkg/aiven/service"
@@ -19,12 +21,22 @@ import (
 	"github.com/nais/kafkarator/controllers"
 	"github.com/nais/kafkarator/pkg/aiven"
 	"github.com/nais/kafkarator/pkg/aiven/acl"
+	"github.com/nais/kafkarator/pkg/aiven/schema"
 	topic_package "github.com/nais/kafkarator/pkg/aiven/topic"
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
 	kafkaratormetrics "github.com/nais/kafkarator/pkg/metrics"
+	"github.com/nais/kafkarator/pkg/policy"
 	"github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"gotest.tools/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 const (
@@ -48,6 +60,7 @@ type aivenSpec struct {
 	Existing aivenData
 	Updated  aivenUpdated
 	Missing  aivenMissing
+	Failing  aivenFailing
 }
 
 type aivenCreated struct {
@@ -68,14 +81,34 @@ type aivenMissing struct {
 	Topics []string
 }
 
+// aivenFailing lists topics that Aiven fails to create.
+type aivenFailing struct {
+	Topics []string
+}
+
 type aivenData struct {
 	Topics []*aiven.KafkaTopic
 	Acls   []*acl.Acl
+	// Kafka service of the pools, used to check topics against the capabilities of the pool if set.
+	Service *aiven.Service
 }
 
 type testCaseConfig struct {
 	Description string
 	Projects    []string
+	KafkaPools  []kafkarator_nais_io_v1alpha1.KafkaPool
+	// Other topics in the cluster.
+	Topics []kafka_nais_io_v1.Topic
+	// Topic configuration policy, in the format of the policy file.
+	Policy json.RawMessage
+	// Data of the topic configuration profile ConfigMap.
+	Profiles map[string]string
+	// Other ConfigMaps in the cluster. Schema registration is enabled if set, but no schemas are registered.
+	ConfigMaps []corev1.ConfigMap
+	// Grace period before the data of deleted topics is deleted, as parsed by time.ParseDuration.
+	DeletionGracePeriod string
+	// ForceRelease allows the force release annotation.
+	ForceRelease bool
 }
 
 func fileReader(file string) io.Reader {
@@ -101,60 +134,96 @@ func aivenMockInterfaces(ctx context.Context, t *testing.T, test testCase) (kafk
 	topicMock := &topic_package.MockInterface{}
 	topicMock.Test(t)
 
-	for _, project := range test.Config.Projects {
+	projects := test.Config.Projects
+	for _, kafkaPool := range test.Config.KafkaPools {
+		projects = append(projects, kafkaPool.Spec.Project)
+	}
+
+	// Topics in Aiven, changed by the create and update calls, so that topics are read back as they were written.
+	topics := make(map[string]*aiven.KafkaTopic)
+	for _, topic := range test.Aiven.Existing.Topics {
+		if len(topic.State) == 0 {
+			topic.State = topic_package.StateActive
+		}
+		topics[topic.TopicName] = topic
+	}
+	getTopic := func(_ context.Context, _, _, name string) (*aiven.KafkaTopic, error) {
+		if topic, ok := topics[name]; ok {
+			return topic, nil
+		}
+		return nil, notFoundError
+	}
+
+	// ACLs in Aiven, changed by the create and delete calls. ACLs are created in parallel.
+	var aclsLock sync.Mutex
+	acls := slices.Clone(test.Aiven.Existing.Acls)
+	listACLs := func(_ context.Context, _, _ string) ([]*acl.Acl, error) {
+		aclsLock.Lock()
+		defer aclsLock.Unlock()
+		return slices.Clone(acls), nil
+	}
+
+	for _, project := range projects {
 		svc, _ := mockNameResolver.ResolveKafkaServiceName(ctx, project)
 		aclMock.
 			On("List", ctx, project, svc).
 			Maybe().
-			Return(test.Aiven.Existing.Acls, nil)
+			Return(listACLs, nil)
+
+		topicMock.
+			On("Get", ctx, project, svc, mock.Anything).
+			Maybe().
+			Return(getTopic)
 
 		for _, topic := range test.Aiven.Missing.Topics {
-			topicMock.
-				On("Get", ctx, project, svc, topic).
-				Maybe().
-				Return(nil, notFoundError)
 			topicMock.
 				On("Delete", ctx, project, svc, topic).
 				Maybe().
 				Return(notFoundError)
 		}
 
-		for _, topic := range test.Aiven.Existing.Topics {
-			topicMock.
-				On("Get", ctx, project, svc, topic.TopicName).
-				Maybe().
-				Return(topic, nil)
-		}
-
 		for _, topic := range test.Aiven.Created.Topics {
-			topicMock.
-				On("Get", ctx, project, svc, topic.TopicName).
-				Maybe().
-				Return(nil, aiven.Error{
-					Status: http.StatusNotFound,
-				})
 			topicMock.
 				On("Create", ctx, project, svc, mock.MatchedBy(utils.TopicCreateReqComp(topic))).
+				Run(func(args mock.Arguments) {
+					req := args.Get(3).(aiven.CreateKafkaTopicRequest)
+					topics[req.TopicName] = applyTopicRequest(nil, req.TopicName, req.Partitions, req.Replication, req.Config, req.Tags)
+				}).
 				Return(nil)
 		}
 
+		for _, name := range test.Aiven.Failing.Topics {
+			topicMock.
+				On("Create", ctx, project, svc, mock.MatchedBy(func(req aiven.CreateKafkaTopicRequest) bool {
+					return req.TopicName == name
+				})).
+				Return(aiven.Error{Message: "Service Unavailable", Status: 503})
+		}
+
 		for _, a := range test.Aiven.Created.Acls {
+			created := &acl.Acl{
+				ID:         wellKnownID,
+				Permission: a.Permission,
+				Topic:      a.Topic,
+				Username:   a.Username,
+			}
 			aclMock.
 				On("Create", ctx, project, svc, a).
-				Return(
-					&acl.Acl{
-						ID:         wellKnownID,
-						Permission: a.Permission,
-						Topic:      a.Topic,
-						Username:   a.Username,
-					},
-					nil,
-				)
+				Run(func(mock.Arguments) {
+					aclsLock.Lock()
+					defer aclsLock.Unlock()
+					acls = append(acls, created)
+				}).
+				Return(created, nil)
 		}
 
 		for topicName, topic := range test.Aiven.Updated.Topics {
 			topicMock.
 				On("Update", ctx, project, svc, topicName, mock.MatchedBy(utils.TopicUpdateReqComp(topic))).
+				Run(func(args mock.Arguments) {
+					name, req := args.String(3), args.Get(4).(aiven.UpdateKafkaTopicRequest)
+					topics[name] = applyTopicRequest(topics[name], name, req.Partitions, req.Replication, req.Config, req.Tags)
+				}).
 				Return(nil)
 		}
 
@@ -167,14 +236,35 @@ func aivenMockInterfaces(ctx context.Context, t *testing.T, test testCase) (kafk
 		for _, a := range test.Aiven.Deleted.Acls {
 			aclMock.
 				On("Delete", ctx, project, svc, a).
+				Run(func(args mock.Arguments) {
+					aclsLock.Lock()
+					defer aclsLock.Unlock()
+					acls = slices.DeleteFunc(acls, func(existing *acl.Acl) bool {
+						return existing.ID == args.String(3)
+					})
+				}).
 				Return(nil)
 		}
 	}
 
+	var schemas schema.Interface
+	if len(test.Config.ConfigMaps) > 0 {
+		schemas = schema.NewMockInterface(t)
+	}
+
+	var capabilities *kafkarator_aiven.CapabilitiesCache
+	if test.Aiven.Existing.Service != nil {
+		serviceMock := service.NewMockInterface(t)
+		serviceMock.On("Get", ctx, mock.Anything, mock.Anything).Return(test.Aiven.Existing.Service, nil)
+		capabilities = kafkarator_aiven.NewCapabilitiesCache(serviceMock, time.Hour)
+	}
+
 	return kafkarator_aiven.Interfaces{
 			ACLs:         aclMock,
 			Topics:       topicMock,
 			NameResolver: mockNameResolver,
+			Capabilities: capabilities,
+			Schemas:      schemas,
 		}, func(t mock.TestingT) bool {
 			result := false
 			if ok := aclMock.AssertExpectations(t); !ok {
@@ -189,6 +279,48 @@ func aivenMockInterfaces(ctx context.Context, t *testing.T, test testCase) (kafk
 		}
 }
 
+// applyTopicRequest returns the topic as Aiven would return it after a create or update request.
+func applyTopicRequest(existing *aiven.KafkaTopic, name string, partitions, replication *int, config aiven.KafkaTopicConfig, tags []aiven.KafkaTopicTag) *aiven.KafkaTopic {
+	topic := &aiven.KafkaTopic{TopicName: name, State: topic_package.StateActive}
+	if existing != nil {
+		clone := *existing
+		topic = &clone
+	}
+	if partitions != nil {
+		topic.Partitions = make([]*aiven.Partition, *partitions)
+		for i := range topic.Partitions {
+			topic.Partitions[i] = &aiven.Partition{Partition: i}
+		}
+	}
+	if replication != nil {
+		topic.Replication = *replication
+	}
+	if tags != nil {
+		topic.Tags = tags
+	}
+
+	// Settings are reported by Aiven on the form {"name": {"value": value}}.
+	settings := make(map[string]any)
+	data, _ := json.Marshal(topic.Config)
+	_ = json.Unmarshal(data, &settings)
+	values := make(map[string]any)
+	data, _ = json.Marshal(config)
+	_ = json.Unmarshal(data, &values)
+	for key, value := range values {
+		settings[key] = map[string]any{"value": value}
+	}
+	data, _ = json.Marshal(settings)
+	topic.Config = aiven.KafkaTopicConfigResponse{}
+	_ = json.Unmarshal(data, &topic.Config)
+	return topic
+}
+
+// profileCache reads the profile ConfigMap from the fake client; its informers are only used by SetupWithManager.
+type profileCache struct {
+	client.Reader
+	cache.Informers
+}
+
 func yamlSubTest(ctx context.Context, t *testing.T, path string) {
 	fixture := fileReader(path)
 	data, err := io.ReadAll(fixture)
@@ -216,24 +348,72 @@ func yamlSubTest(ctx context.Context, t *testing.T, path string) {
 
 	aivenMocks, assertMocks := aivenMockInterfaces(ctx, t, test)
 
+	scheme := runtime.NewScheme()
+	_ = kafkarator_nais_io_v1alpha1.AddToScheme(scheme)
+	_ = kafka_nais_io_v1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range test.Config.KafkaPools {
+		clientBuilder.WithObjects(&test.Config.KafkaPools[i])
+	}
+	for i := range test.Config.Topics {
+		clientBuilder.WithObjects(&test.Config.Topics[i])
+	}
+	for i := range test.Config.ConfigMaps {
+		clientBuilder.WithObjects(&test.Config.ConfigMaps[i])
+	}
+	profileConfigMap := types.NamespacedName{Namespace: "kafkarator", Name: "topic-profiles"}
+	clientBuilder.WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: profileConfigMap.Namespace, Name: profileConfigMap.Name},
+		Data:       test.Config.Profiles,
+	})
+	k8sClient := clientBuilder.Build()
+
 	reconciler := controllers.TopicReconciler{
-		Aiven:    aivenMocks,
-		Logger:   log.New(),
-		Projects: test.Config.Projects,
+		Client:           k8sClient,
+		Aiven:            aivenMocks,
+		Logger:           log.New(),
+		Projects:         test.Config.Projects,
+		ProfileConfigMap: profileConfigMap,
+		ProfileCache:     profileCache{Reader: k8sClient},
+		ClusterName:      "test-cluster",
+		ForceRelease:     test.Config.ForceRelease,
+	}
+	if len(test.Config.DeletionGracePeriod) > 0 {
+		reconciler.DeletionGracePeriod, err = time.ParseDuration(test.Config.DeletionGracePeriod)
+		if err != nil {
+			t.Errorf("unable to parse deletion grace period: %s", err)
+			return
+		}
+	}
+	if test.Config.Policy != nil {
+		policyFile := filepath.Join(t.TempDir(), "policy.json")
+		err = os.WriteFile(policyFile, test.Config.Policy, 0o600)
+		if err == nil {
+			reconciler.Policy, err = policy.NewStore(policyFile, log.New())
+		}
+		if err != nil {
+			t.Errorf("unable to load policy: %s", err)
+			return
+		}
 	}
 
 	result := reconciler.Process(ctx, *topic, log.NewEntry(log.StandardLogger()))
 	if test.Error != nil {
-		assert.Equal(t, result.Error, *test.Error)
+		assert.Equal(t, result.Error.Error(), *test.Error)
 		return
 	}
 
 	// hard to test current time with static data
 	test.Output.Status.SynchronizationTime = result.Status.SynchronizationTime
 	test.Output.Status.SynchronizationHash = result.Status.SynchronizationHash
+	test.Output.Status.LatestAivenSyncFailure = result.Status.LatestAivenSyncFailure
 
 	assert.DeepEqual(t, test.Output.Status, result.Status)
 	assert.Equal(t, test.Output.Requeue, result.Requeue)
+	for key, value := range test.Output.Annotations {
+		assert.Equal(t, value, result.Annotations[key])
+	}
 	assertMocks(t)
 }
 
@@ -2,8 +2,11 @@ package acl
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/nais/kafkarator/pkg/freeze"
 	"github.com/nais/kafkarator/pkg/metrics"
 	"github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
 	log "github.com/sirupsen/logrus"
@@ -22,37 +25,37 @@ type Source interface {
 }
 
 type Manager struct {
-	AivenACLs Interface
-	Project   string
-	Service   string
-	Source    Source
-	Logger    log.FieldLogger
-	DryRun    bool
+	AivenACLs   Interface
+	Project     string
+	Service     string
+	Source      Source
+	Logger      log.FieldLogger
+	DryRun      bool
+	Concurrency int
+	// Freeze defers creating and deleting ACLs while the pool is frozen. Changes are allowed if nil.
+	Freeze *freeze.Freeze
 }
 
 // Synchronize Syncs the ACL spec in the Source resource with Aiven.
 //
 //	Missing ACL definitions are created, unnecessary definitions are deleted.
+//	Up to Concurrency calls run in parallel, and all failed calls are reported in the returned error.
 func (r *Manager) Synchronize(ctx context.Context) error {
-	existingAcls, err := r.getExistingAcls(ctx)
+	changes, err := r.Plan(ctx)
 	if err != nil {
 		return err
 	}
 
-	wantedAcls, err := r.getWantedAcls(r.Source.TopicName(), r.Source.ACLs())
-	if err != nil {
+	if err = r.Freeze.Check(changes.Describe()...); err != nil {
 		return err
 	}
 
-	toAdd := NewACLs(existingAcls, wantedAcls)
-	toDelete := DeleteACLs(existingAcls, wantedAcls)
-
-	err = r.add(ctx, toAdd)
+	err = r.add(ctx, changes.Add)
 	if err != nil {
 		return err
 	}
 
-	err = r.delete(ctx, toDelete)
+	err = r.delete(ctx, changes.Delete)
 	if err != nil {
 		return err
 	}
@@ -60,6 +63,49 @@ func (r *Manager) Synchronize(ctx context.Context) error {
 	return nil
 }
 
+// Changes are the ACLs Synchronize creates and deletes for a topic.
+type Changes struct {
+	Existing []Acl
+	Add      []Acl
+	Delete   []Acl
+}
+
+// Describe lists the changes in a human readable form.
+func (c *Changes) Describe() []string {
+	described := make([]string, 0, len(c.Add)+len(c.Delete))
+	for _, a := range c.Add {
+		described = append(described, fmt.Sprintf("create ACL %s for %s on %s", a.Permission, a.Username, a.Topic))
+	}
+	for _, a := range c.Delete {
+		described = append(described, fmt.Sprintf("delete ACL %s for %s on %s", a.Permission, a.Username, a.Topic))
+	}
+	return described
+}
+
+// Plan returns the changes Synchronize would make, without changing anything.
+func (r *Manager) Plan(ctx context.Context) (*Changes, error) {
+	existingAcls, err := r.getExistingAcls(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wantedAcls, err := r.getWantedAcls(r.Source.TopicName(), r.Source.ACLs())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Changes{
+		Existing: existingAcls,
+		Add:      NewACLs(existingAcls, wantedAcls),
+		Delete:   DeleteACLs(existingAcls, wantedAcls),
+	}, nil
+}
+
+// Existing returns the ACLs of the topic in Aiven.
+func (r *Manager) Existing(ctx context.Context) ([]Acl, error) {
+	return r.getExistingAcls(ctx)
+}
+
 func (r *Manager) getExistingAcls(ctx context.Context) ([]Acl, error) {
 	var kafkaAcls []*Acl
 	err := metrics.ObserveAivenLatency("ACL_List", r.Project, func() error {
@@ -90,7 +136,7 @@ func (r *Manager) getWantedAcls(topic string, topicAcls []kafka_nais_io_v1.Topic
 }
 
 func (r *Manager) add(ctx context.Context, toAdd []Acl) error {
-	for _, acl := range toAdd {
+	return parallel(r.Concurrency, toAdd, func(acl Acl) error {
 		req := CreateKafkaACLRequest{
 			Permission: acl.Permission,
 			Topic:      acl.Topic,
@@ -114,12 +160,12 @@ func (r *Manager) add(ctx context.Context, toAdd []Acl) error {
 			"acl_username":   req.Username,
 			"acl_permission": req.Permission,
 		}).Infof("Created ACL entry")
-	}
-	return nil
+		return nil
+	})
 }
 
 func (r *Manager) delete(ctx context.Context, toDelete []Acl) error {
-	for _, acl := range toDelete {
+	return parallel(r.Concurrency, toDelete, func(acl Acl) error {
 		if len(acl.ID) == 0 {
 			return fmt.Errorf("attemping to delete acl without ID: %v", acl)
 		}
@@ -139,8 +185,27 @@ func (r *Manager) delete(ctx context.Context, toDelete []Acl) error {
 			"acl_username":   acl.Username,
 			"acl_permission": acl.Permission,
 		}).Infof("Deleted ACL entry")
+		return nil
+	})
+}
+
+// parallel calls fun for every item, with at most concurrency calls in flight.
+// Errors from all calls are joined together instead of stopping at the first failure.
+func parallel[T any](concurrency int, items []T, fun func(item T) error) error {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, max(concurrency, 1))
+	errs := make([]error, len(items))
+
+	for i, item := range items {
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			errs[i] = fun(item)
+		})
 	}
-	return nil
+	wg.Wait()
+
+	return errors.Join(errs...)
 }
 
 // NewACLs given a list of ACL specs, return a new list of ACL objects that does not already exist
@@ -2,8 +2,11 @@ package acl_test
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp/cmpopts"
 	log "github.com/sirupsen/logrus"
@@ -335,6 +338,146 @@ func (suite *ACLFilterTestSuite) TestSynchronizeStreamWithAdditionalUsers() {
 	}
 }
 
+func (suite *ACLFilterTestSuite) TestSynchronizeCollectsAllErrors() {
+	ctx := context.Background()
+	source := kafka_nais_io_v1.Topic{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Topic,
+			Namespace: Team,
+		},
+		Spec: kafka_nais_io_v1.TopicSpec{
+			Pool: TestPool,
+			ACL:  suite.topicAcls,
+		},
+	}
+
+	var inFlight, maxInFlight atomic.Int32
+	m := &acl.MockInterface{}
+	m.On("List", ctx, TestPool, TestService).
+		Once().
+		Return([]*acl.Acl{}, nil)
+	m.On("Create", ctx, TestPool, TestService, mock.Anything).
+		Times(3).
+		Run(func(args mock.Arguments) {
+			n := inFlight.Add(1)
+			for {
+				old := maxInFlight.Load()
+				if n <= old || maxInFlight.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			inFlight.Add(-1)
+		}).
+		Return(nil, errors.New("aiven is down"))
+
+	aclManager := acl.Manager{
+		AivenACLs:   m,
+		Project:     TestPool,
+		Service:     TestService,
+		Source:      acl.TopicAdapter{Topic: &source},
+		Logger:      log.New(),
+		Concurrency: 2,
+	}
+
+	err := aclManager.Synchronize(ctx)
+	suite.Error(err)
+	suite.Len(err.(interface{ Unwrap() []error }).Unwrap(), 3)
+	suite.LessOrEqual(maxInFlight.Load(), int32(2))
+
+	m.AssertExpectations(suite.T())
+}
+
+func (suite *ACLFilterTestSuite) TestSynchronizeSchemaRegistry() {
+	ctx := context.Background()
+	source := kafka_nais_io_v1.Topic{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Topic,
+			Namespace: Team,
+		},
+		Spec: kafka_nais_io_v1.TopicSpec{
+			Pool: TestPool,
+			ACL:  suite.topicAcls,
+		},
+	}
+
+	existing := []*acl.SchemaRegistryAcl{
+		{ // Keep
+			ID:         "keep",
+			Permission: acl.SchemaRegistryWrite,
+			Resource:   "Subject:" + FullTopic + "-value",
+			Username:   "user2_app_eb343e9a_*",
+		},
+		{ // Delete because access was removed from the topic
+			ID:         "remove",
+			Permission: acl.SchemaRegistryRead,
+			Resource:   "Subject:" + FullTopic + "-key",
+			Username:   "gone_app_12345678_*",
+		},
+		{ // Ignore because it belongs to another topic
+			ID:         "other",
+			Permission: acl.SchemaRegistryRead,
+			Resource:   "Subject:test.other-topic-value",
+			Username:   "gone_app_12345678_*",
+		},
+	}
+
+	created := make(chan acl.CreateSchemaRegistryACLRequest, 6)
+	m := &acl.MockSchemaRegistryInterface{}
+	m.On("List", ctx, TestPool, TestService).
+		Once().
+		Return(existing, nil)
+	m.On("Create", ctx, TestPool, TestService, mock.Anything).
+		Times(5).
+		Run(func(args mock.Arguments) {
+			created <- args.Get(3).(acl.CreateSchemaRegistryACLRequest)
+		}).
+		Return(nil, nil)
+	m.On("Delete", ctx, TestPool, TestService, "remove").
+		Once().
+		Return(nil)
+
+	manager := acl.SchemaRegistryManager{
+		AivenSchemaRegistryACLs: m,
+		Project:                 TestPool,
+		Service:                 TestService,
+		Source:                  acl.TopicAdapter{Topic: &source},
+		Logger:                  log.New(),
+		Concurrency:             2,
+	}
+
+	err := manager.Synchronize(ctx)
+	suite.NoError(err)
+	m.AssertExpectations(suite.T())
+
+	close(created)
+	for req := range created {
+		if strings.HasPrefix(req.Username, "user_app_") {
+			suite.Equal(acl.SchemaRegistryRead, req.Permission)
+		} else {
+			suite.Equal(acl.SchemaRegistryWrite, req.Permission)
+		}
+	}
+}
+
+func TestParseConcurrency(t *testing.T) {
+	c, err := acl.ParseConcurrency(4, []string{"big-pool=16", "small-pool=1"})
+	assert.NilError(t, err)
+	assert.Equal(t, 16, c.For("big-pool"))
+	assert.Equal(t, 1, c.For("small-pool"))
+	assert.Equal(t, 4, c.For("other-pool"))
+
+	_, err = acl.ParseConcurrency(4, []string{"big-pool"})
+	assert.ErrorContains(t, err, "expected project=N")
+
+	_, err = acl.ParseConcurrency(4, []string{"big-pool=0"})
+	assert.ErrorContains(t, err, "positive integer")
+
+	c, err = acl.ParseConcurrency(0, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, c.For("any-pool"))
+}
+
 func TestACLFilter(t *testing.T) {
 	testSuite := new(ACLFilterTestSuite)
 	suite.Run(t, testSuite)
@@ -0,0 +1,41 @@
+package acl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Concurrency decides how many ACL create and delete calls may run in parallel against an Aiven project.
+type Concurrency struct {
+	Default  int
+	Projects map[string]int
+}
+
+// ParseConcurrency builds a Concurrency from a default value and a list of per-project overrides on the form `project=N`.
+func ParseConcurrency(dflt int, overrides []string) (Concurrency, error) {
+	c := Concurrency{
+		Default:  dflt,
+		Projects: make(map[string]int, len(overrides)),
+	}
+	for _, override := range overrides {
+		project, value, found := strings.Cut(override, "=")
+		if !found || len(project) == 0 {
+			return Concurrency{}, fmt.Errorf("invalid ACL concurrency override '%s'; expected project=N", override)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return Concurrency{}, fmt.Errorf("invalid ACL concurrency for project '%s': must be a positive integer", project)
+		}
+		c.Projects[project] = n
+	}
+	return c, nil
+}
+
+// For returns the number of parallel ACL calls allowed for a project.
+func (c Concurrency) For(project string) int {
+	if n, ok := c.Projects[project]; ok {
+		return n
+	}
+	return max(c.Default, 1)
+}
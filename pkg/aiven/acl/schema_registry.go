@@ -0,0 +1,234 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/freeze"
+	"github.com/nais/kafkarator/pkg/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	SchemaRegistryRead  = "schema_registry_read"
+	SchemaRegistryWrite = "schema_registry_write"
+)
+
+type SchemaRegistryInterface interface {
+	List(ctx context.Context, project, serviceName string) ([]*SchemaRegistryAcl, error)
+	Create(ctx context.Context, project, service string, req CreateSchemaRegistryACLRequest) (*SchemaRegistryAcl, error)
+	Delete(ctx context.Context, project, service, aclID string) error
+}
+
+type SchemaRegistrySource interface {
+	// SchemaRegistryResources lists every schema registry resource owned by the source,
+	// whether or not any ACL entries should exist for it.
+	SchemaRegistryResources() []string
+	SchemaRegistryACLs() (SchemaRegistryAcls, error)
+}
+
+type SchemaRegistryAcl struct {
+	ID         string
+	Permission string
+	Resource   string
+	Username   string
+}
+
+type SchemaRegistryAcls []SchemaRegistryAcl
+
+type CreateSchemaRegistryACLRequest struct {
+	Permission string `json:"permission"`
+	Resource   string `json:"resource"`
+	Username   string `json:"username"`
+}
+
+func FromKafkaSchemaRegistryACL(kafkaAcl *aiven.KafkaSchemaRegistryACL) SchemaRegistryAcl {
+	return SchemaRegistryAcl{
+		ID:         kafkaAcl.ID,
+		Permission: kafkaAcl.Permission,
+		Resource:   kafkaAcl.Resource,
+		Username:   kafkaAcl.Username,
+	}
+}
+
+func (a *SchemaRegistryAcls) Contains(other SchemaRegistryAcl) bool {
+	for _, mine := range *a {
+		if mine.Username == other.Username &&
+			mine.Resource == other.Resource &&
+			mine.Permission == other.Permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (a SchemaRegistryAcl) String() string {
+	return fmt.Sprintf("SchemaRegistryAcl{Username:'%s', Permission:'%s', Resource:'%s', ID:'%s'}",
+		a.Username, a.Permission, a.Resource, a.ID)
+}
+
+// SubjectResources returns the schema registry resources for the key and value subjects of a topic,
+// using the default TopicNameStrategy for subject names.
+func SubjectResources(topic string) []string {
+	return []string{
+		fmt.Sprintf("Subject:%s-key", topic),
+		fmt.Sprintf("Subject:%s-value", topic),
+	}
+}
+
+// SchemaRegistryPermission maps topic access to schema registry access.
+// Producers need to register schemas, so any write access grants schema registry write, which also allows reads.
+func SchemaRegistryPermission(access string) string {
+	if access == "read" {
+		return SchemaRegistryRead
+	}
+	return SchemaRegistryWrite
+}
+
+type SchemaRegistryManager struct {
+	AivenSchemaRegistryACLs SchemaRegistryInterface
+	Project                 string
+	Service                 string
+	Source                  SchemaRegistrySource
+	Logger                  log.FieldLogger
+	DryRun                  bool
+	Concurrency             int
+	// Freeze defers creating and deleting ACLs while the pool is frozen. Changes are allowed if nil.
+	Freeze *freeze.Freeze
+}
+
+// Synchronize Syncs the schema registry ACLs derived from the Source resource with Aiven.
+//
+//	Missing ACL definitions are created, unnecessary definitions are deleted.
+func (r *SchemaRegistryManager) Synchronize(ctx context.Context) error {
+	var kafkaAcls []*SchemaRegistryAcl
+	err := metrics.ObserveAivenLatency("SchemaRegistryACL_List", r.Project, func() error {
+		var err error
+		kafkaAcls, err = r.AivenSchemaRegistryACLs.List(ctx, r.Project, r.Service)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	resources := r.Source.SchemaRegistryResources()
+	existingAcls := make(SchemaRegistryAcls, 0, len(kafkaAcls))
+	for _, kafkaAcl := range kafkaAcls {
+		if slices.Contains(resources, kafkaAcl.Resource) {
+			existingAcls = append(existingAcls, *kafkaAcl)
+		}
+	}
+
+	wantedAcls, err := r.Source.SchemaRegistryACLs()
+	if err != nil {
+		return err
+	}
+
+	toAdd := make([]SchemaRegistryAcl, 0, len(wantedAcls))
+	for _, wantedAcl := range wantedAcls {
+		if !existingAcls.Contains(wantedAcl) {
+			toAdd = append(toAdd, wantedAcl)
+		}
+	}
+
+	toDelete := make([]SchemaRegistryAcl, 0, len(existingAcls))
+	for _, existingAcl := range existingAcls {
+		if !wantedAcls.Contains(existingAcl) {
+			toDelete = append(toDelete, existingAcl)
+		}
+	}
+
+	changes := make([]string, 0, len(toAdd)+len(toDelete))
+	for _, a := range toAdd {
+		changes = append(changes, fmt.Sprintf("create schema registry ACL %s for %s on %s", a.Permission, a.Username, a.Resource))
+	}
+	for _, a := range toDelete {
+		changes = append(changes, fmt.Sprintf("delete schema registry ACL %s for %s on %s", a.Permission, a.Username, a.Resource))
+	}
+	if err = r.Freeze.Check(changes...); err != nil {
+		return err
+	}
+
+	err = parallel(r.Concurrency, toAdd, func(acl SchemaRegistryAcl) error {
+		req := CreateSchemaRegistryACLRequest{
+			Permission: acl.Permission,
+			Resource:   acl.Resource,
+			Username:   acl.Username,
+		}
+		err := metrics.ObserveAivenLatency("SchemaRegistryACL_Create", r.Project, func() error {
+			if r.DryRun {
+				r.Logger.Infof("DRY RUN: Would create schema registry ACL entry: %v", req)
+				return nil
+			}
+			_, err := r.AivenSchemaRegistryACLs.Create(ctx, r.Project, r.Service, req)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		r.Logger.WithFields(log.Fields{
+			"acl_username":   req.Username,
+			"acl_permission": req.Permission,
+			"acl_resource":   req.Resource,
+		}).Infof("Created schema registry ACL entry")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return parallel(r.Concurrency, toDelete, func(acl SchemaRegistryAcl) error {
+		if len(acl.ID) == 0 {
+			return fmt.Errorf("attemping to delete schema registry acl without ID: %v", acl)
+		}
+		err := metrics.ObserveAivenLatency("SchemaRegistryACL_Delete", r.Project, func() error {
+			if r.DryRun {
+				r.Logger.Infof("DRY RUN: Would delete schema registry ACL entry: %v", acl)
+				return nil
+			}
+			return r.AivenSchemaRegistryACLs.Delete(ctx, r.Project, r.Service, acl.ID)
+		})
+		if err != nil {
+			return err
+		}
+
+		r.Logger.WithFields(log.Fields{
+			"acl_id":         acl.ID,
+			"acl_username":   acl.Username,
+			"acl_permission": acl.Permission,
+			"acl_resource":   acl.Resource,
+		}).Infof("Deleted schema registry ACL entry")
+		return nil
+	})
+}
+
+func (t TopicAdapter) SchemaRegistryResources() []string {
+	return SubjectResources(t.FullName())
+}
+
+func (t TopicAdapter) SchemaRegistryACLs() (SchemaRegistryAcls, error) {
+	resources := t.SchemaRegistryResources()
+	acls := make(SchemaRegistryAcls, 0, len(t.Spec.ACL)*len(resources))
+	for _, topicAcl := range t.Spec.ACL {
+		username, err := topicAcl.ServiceUserNameWithSuffix("*")
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources {
+			acl := SchemaRegistryAcl{
+				Permission: SchemaRegistryPermission(topicAcl.Access),
+				Resource:   resource,
+				Username:   username,
+			}
+			if !acls.Contains(acl) {
+				acls = append(acls, acl)
+			}
+		}
+	}
+	return acls, nil
+}
+
+var _ SchemaRegistrySource = TopicAdapter{}
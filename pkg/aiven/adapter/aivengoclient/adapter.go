@@ -41,3 +41,38 @@ func (c *AclClient) Create(ctx context.Context, project, service string, req acl
 func (c *AclClient) Delete(ctx context.Context, project, service, aclID string) error {
 	return c.KafkaACLHandler.Delete(ctx, project, service, aclID)
 }
+
+type SchemaRegistryAclClient struct {
+	*aiven.KafkaSchemaRegistryACLHandler
+}
+
+func (c *SchemaRegistryAclClient) List(ctx context.Context, project, serviceName string) ([]*acl.SchemaRegistryAcl, error) {
+	out, err := c.KafkaSchemaRegistryACLHandler.List(ctx, project, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	acls := make([]*acl.SchemaRegistryAcl, 0, len(out))
+	for _, aclOut := range out {
+		acls = append(acls, new(acl.FromKafkaSchemaRegistryACL(aclOut)))
+	}
+	return acls, nil
+}
+
+func (c *SchemaRegistryAclClient) Create(ctx context.Context, project, service string, req acl.CreateSchemaRegistryACLRequest) (*acl.SchemaRegistryAcl, error) {
+	in := aiven.CreateKafkaSchemaRegistryACLRequest{
+		Permission: req.Permission,
+		Resource:   req.Resource,
+		Username:   req.Username,
+	}
+	out, err := c.KafkaSchemaRegistryACLHandler.Create(ctx, project, service, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(acl.FromKafkaSchemaRegistryACL(out)), nil
+}
+
+func (c *SchemaRegistryAclClient) Delete(ctx context.Context, project, service, aclID string) error {
+	return c.KafkaSchemaRegistryACLHandler.Delete(ctx, project, service, aclID)
+}
@@ -6,6 +6,7 @@ import (
 
 	generatedclient "github.com/aiven/go-client-codegen"
 	"github.com/aiven/go-client-codegen/handler/kafka"
+	"github.com/aiven/go-client-codegen/handler/kafkaschemaregistry"
 	"github.com/nais/kafkarator/pkg/aiven/acl"
 )
 
@@ -74,3 +75,61 @@ func makeAcl(aclOut *kafka.AclOut) *acl.Acl {
 		Username:   aclOut.Username,
 	}
 }
+
+type SchemaRegistryAclClient struct {
+	generatedclient.Client
+}
+
+func (c *SchemaRegistryAclClient) List(ctx context.Context, project, serviceName string) ([]*acl.SchemaRegistryAcl, error) {
+	out, err := c.ServiceSchemaRegistryAclList(ctx, project, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	acls := make([]*acl.SchemaRegistryAcl, 0, len(out))
+	for _, aclOut := range out {
+		acls = append(acls, makeSchemaRegistryAcl(&aclOut))
+	}
+	return acls, nil
+}
+
+func (c *SchemaRegistryAclClient) Create(ctx context.Context, project, service string, req acl.CreateSchemaRegistryACLRequest) (*acl.SchemaRegistryAcl, error) {
+	in := &kafkaschemaregistry.ServiceSchemaRegistryAclAddIn{
+		Permission: kafkaschemaregistry.PermissionType(req.Permission),
+		Resource:   req.Resource,
+		Username:   req.Username,
+	}
+	out, err := c.ServiceSchemaRegistryAclAdd(ctx, project, service, in)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like for Kafka ACLs, the server returns every schema registry ACL currently defined.
+	// Assume the one that was created is the last one matching.
+	var foundACL *kafkaschemaregistry.AclOut
+	for _, aclOut := range out {
+		if aclOut.Permission == in.Permission && aclOut.Resource == in.Resource && aclOut.Username == in.Username {
+			foundACL = &aclOut
+		}
+	}
+
+	if foundACL == nil {
+		return nil, fmt.Errorf("created schema registry ACL not found from response ACL list")
+	}
+
+	return makeSchemaRegistryAcl(foundACL), nil
+}
+
+func (c *SchemaRegistryAclClient) Delete(ctx context.Context, project, service, aclID string) error {
+	_, err := c.ServiceSchemaRegistryAclDelete(ctx, project, service, aclID)
+	return err
+}
+
+func makeSchemaRegistryAcl(aclOut *kafkaschemaregistry.AclOut) *acl.SchemaRegistryAcl {
+	return &acl.SchemaRegistryAcl{
+		ID:         valueOrEmpty(aclOut.Id),
+		Permission: string(aclOut.Permission),
+		Resource:   aclOut.Resource,
+		Username:   aclOut.Username,
+	}
+}
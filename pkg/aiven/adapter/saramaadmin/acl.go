@@ -0,0 +1,244 @@
+package saramaadmin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/nais/kafkarator/pkg/aiven/acl"
+)
+
+// markerPrefix prefixes the transactional id of the marker ACL recorded for every Aiven style ACL entry.
+//
+// Aiven ACL entries are independent records, while Kafka ACLs are a set of operations per principal and resource.
+// An entry granting read overlaps with an entry granting readwrite, so the Aiven entries can not be derived from
+// the Kafka ACLs alone. Each entry is therefore recorded as a DESCRIBE grant on the transactional id
+// kafkarator/<permission>/<topic>, which gives no access to any data, and the topic operations granted to a
+// principal are always the union of the operations of its recorded entries.
+const markerPrefix = "kafkarator/"
+
+// permissionOperations maps the Aiven permission model to Kafka ACL operations.
+// Read and write both imply describe in Kafka.
+var permissionOperations = map[string][]sarama.AclOperation{
+	"read":      {sarama.AclOperationRead},
+	"write":     {sarama.AclOperationWrite},
+	"readwrite": {sarama.AclOperationRead, sarama.AclOperationWrite},
+	"admin":     {sarama.AclOperationAll},
+}
+
+// AclClient manages topic ACLs as native Kafka ACLs.
+//
+// Usernames are used verbatim as principals, so usernames with wildcards are only honored by brokers
+// running an authorizer that understands them. Clusters without an authorizer answer ACL requests with SECURITY_DISABLED,
+// and allow all access; they are regarded as having no ACLs, and creating or deleting ACLs in them does nothing.
+type AclClient struct {
+	Admins *Admins
+}
+
+var _ acl.Interface = &AclClient{}
+
+// securityDisabled returns true if the error is from a cluster without an authorizer.
+func securityDisabled(err error) bool {
+	return errors.Is(err, sarama.ErrSecurityDisabled)
+}
+
+func principal(username string) string {
+	return "User:" + username
+}
+
+func marker(permission, topic string) string {
+	return markerPrefix + permission + "/" + topic
+}
+
+// entryID identifies an entry by its permission, topic and username, none of which can contain a slash.
+func entryID(permission, topic, username string) string {
+	return strings.Join([]string{permission, topic, username}, "/")
+}
+
+func parseAclID(id string) (permission, topic, username string, err error) {
+	parts := strings.SplitN(id, "/", 3)
+	if len(parts) != 3 {
+		return "", "", "", fmt.Errorf("invalid ACL id '%s'", id)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+// topicResource converts an Aiven topic pattern to a Kafka resource.
+// A trailing wildcard becomes a prefixed resource, while a single wildcard matches every topic in Kafka as well.
+func topicResource(topic string) sarama.Resource {
+	resource := sarama.Resource{
+		ResourceType:        sarama.AclResourceTopic,
+		ResourceName:        topic,
+		ResourcePatternType: sarama.AclPatternLiteral,
+	}
+	if topic != "*" && strings.HasSuffix(topic, "*") {
+		resource.ResourceName = strings.TrimSuffix(topic, "*")
+		resource.ResourcePatternType = sarama.AclPatternPrefixed
+	}
+	return resource
+}
+
+func markerResource(permission, topic string) sarama.Resource {
+	return sarama.Resource{
+		ResourceType:        sarama.AclResourceTransactionalID,
+		ResourceName:        marker(permission, topic),
+		ResourcePatternType: sarama.AclPatternLiteral,
+	}
+}
+
+func allow(username string, operation sarama.AclOperation) *sarama.Acl {
+	return &sarama.Acl{
+		Principal:      principal(username),
+		Host:           "*",
+		Operation:      operation,
+		PermissionType: sarama.AclPermissionAllow,
+	}
+}
+
+// markers lists the ACL entries recorded in the cluster, optionally limited to a single username.
+func (c *AclClient) markers(admin sarama.ClusterAdmin, username *string) ([]*acl.Acl, error) {
+	filter := sarama.AclFilter{
+		ResourceType:              sarama.AclResourceTransactionalID,
+		ResourcePatternTypeFilter: sarama.AclPatternLiteral,
+		Operation:                 sarama.AclOperationDescribe,
+		PermissionType:            sarama.AclPermissionAllow,
+	}
+	if username != nil {
+		filter.Principal = new(principal(*username))
+	}
+
+	resourceAcls, err := admin.ListAcls(filter)
+	if securityDisabled(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	acls := make([]*acl.Acl, 0, len(resourceAcls))
+	for _, resourceAcl := range resourceAcls {
+		name, found := strings.CutPrefix(resourceAcl.ResourceName, markerPrefix)
+		if !found {
+			continue
+		}
+		permission, topic, found := strings.Cut(name, "/")
+		if !found {
+			continue
+		}
+		for _, kafkaAcl := range resourceAcl.Acls {
+			username, found := strings.CutPrefix(kafkaAcl.Principal, "User:")
+			if !found {
+				continue
+			}
+			acls = append(acls, &acl.Acl{
+				ID:         entryID(permission, topic, username),
+				Permission: permission,
+				Topic:      topic,
+				Username:   username,
+			})
+		}
+	}
+	return acls, nil
+}
+
+func (c *AclClient) List(_ context.Context, _, serviceName string) ([]*acl.Acl, error) {
+	admin, err := c.Admins.Admin(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return c.markers(admin, nil)
+}
+
+func (c *AclClient) Create(_ context.Context, _, service string, req acl.CreateKafkaACLRequest) (*acl.Acl, error) {
+	operations, ok := permissionOperations[req.Permission]
+	if !ok {
+		return nil, fmt.Errorf("unsupported ACL permission '%s'", req.Permission)
+	}
+
+	admin, err := c.Admins.Admin(service)
+	if err != nil {
+		return nil, err
+	}
+
+	topicAcls := &sarama.ResourceAcls{Resource: topicResource(req.Topic)}
+	for _, operation := range operations {
+		topicAcls.Acls = append(topicAcls.Acls, allow(req.Username, operation))
+	}
+
+	err = admin.CreateACLs([]*sarama.ResourceAcls{
+		topicAcls,
+		{
+			Resource: markerResource(req.Permission, req.Topic),
+			Acls:     []*sarama.Acl{allow(req.Username, sarama.AclOperationDescribe)},
+		},
+	})
+	if err != nil && !securityDisabled(err) {
+		return nil, err
+	}
+
+	return &acl.Acl{
+		ID:         entryID(req.Permission, req.Topic, req.Username),
+		Permission: req.Permission,
+		Topic:      req.Topic,
+		Username:   req.Username,
+	}, nil
+}
+
+// Delete removes the entry, and every topic operation not granted by another entry for the same username and topic.
+func (c *AclClient) Delete(_ context.Context, _, service, aclID string) error {
+	permission, topic, username, err := parseAclID(aclID)
+	if err != nil {
+		return err
+	}
+
+	admin, err := c.Admins.Admin(service)
+	if err != nil {
+		return err
+	}
+
+	err = c.deleteAcl(admin, markerResource(permission, topic), username, sarama.AclOperationDescribe)
+	if err != nil {
+		return err
+	}
+
+	remaining, err := c.markers(admin, &username)
+	if err != nil {
+		return err
+	}
+	granted := make([]sarama.AclOperation, 0)
+	for _, other := range remaining {
+		if other.Topic == topic {
+			granted = append(granted, permissionOperations[other.Permission]...)
+		}
+	}
+
+	for _, operation := range permissionOperations[permission] {
+		if slices.Contains(granted, operation) {
+			continue
+		}
+		err = c.deleteAcl(admin, topicResource(topic), username, operation)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *AclClient) deleteAcl(admin sarama.ClusterAdmin, resource sarama.Resource, username string, operation sarama.AclOperation) error {
+	_, err := admin.DeleteACL(sarama.AclFilter{
+		ResourceType:              resource.ResourceType,
+		ResourceName:              new(resource.ResourceName),
+		ResourcePatternTypeFilter: resource.ResourcePatternType,
+		Principal:                 new(principal(username)),
+		Host:                      new("*"),
+		Operation:                 operation,
+		PermissionType:            sarama.AclPermissionAllow,
+	}, false)
+	if securityDisabled(err) {
+		return nil
+	}
+	return err
+}
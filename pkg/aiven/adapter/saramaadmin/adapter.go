@@ -0,0 +1,78 @@
+// Package saramaadmin implements the topic and ACL interfaces on top of a plain Kafka cluster,
+// using the Kafka admin API through sarama.
+//
+// It is intended for local development, where a Kafka cluster running next to the Kubernetes cluster
+// replaces the Aiven project. Pools are mapped to bootstrap brokers, and the resolved service name
+// is the comma separated list of brokers used to connect to the cluster.
+package saramaadmin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/IBM/sarama"
+	"github.com/nais/liberator/pkg/aiven/service"
+)
+
+// NameResolver resolves the service name of a pool to its bootstrap brokers.
+type NameResolver struct {
+	Pools map[string][]string
+}
+
+func (r *NameResolver) ResolveKafkaServiceName(_ context.Context, project string) (string, error) {
+	brokers := r.Pools[project]
+	if len(brokers) == 0 {
+		return "", fmt.Errorf("no kafka brokers configured for pool %s", project)
+	}
+	return strings.Join(brokers, ","), nil
+}
+
+var _ service.NameResolver = &NameResolver{}
+
+// ParsePools parses a list of pool brokers on the form pool=host:port.
+// A pool can be listed more than once to configure several bootstrap brokers.
+func ParsePools(entries []string) (map[string][]string, error) {
+	pools := make(map[string][]string, len(entries))
+	for _, entry := range entries {
+		pool, broker, found := strings.Cut(entry, "=")
+		if !found || len(pool) == 0 || len(broker) == 0 {
+			return nil, fmt.Errorf("invalid pool brokers '%s'; expected pool=host:port", entry)
+		}
+		pools[pool] = append(pools[pool], broker)
+	}
+	return pools, nil
+}
+
+// Admins holds one cluster admin per Kafka cluster, connecting on first use.
+type Admins struct {
+	Config *sarama.Config
+
+	lock   sync.Mutex
+	admins map[string]sarama.ClusterAdmin
+}
+
+func NewAdmins(config *sarama.Config) *Admins {
+	return &Admins{
+		Config: config,
+		admins: make(map[string]sarama.ClusterAdmin),
+	}
+}
+
+// Admin returns the cluster admin for a service name as returned by NameResolver.
+func (a *Admins) Admin(service string) (sarama.ClusterAdmin, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if admin, ok := a.admins[service]; ok {
+		return admin, nil
+	}
+
+	admin, err := sarama.NewClusterAdmin(strings.Split(service, ","), a.Config)
+	if err != nil {
+		return nil, fmt.Errorf("connect to kafka brokers %s: %w", service, err)
+	}
+	a.admins[service] = admin
+	return admin, nil
+}
@@ -0,0 +1,363 @@
+package saramaadmin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nais/kafkarator/pkg/aiven/acl"
+	"github.com/nais/kafkarator/pkg/aiven/adapter/saramaadmin"
+)
+
+const (
+	pool     = "local"
+	topic    = "myteam.mytopic"
+	username = "myteam_myapp_*"
+)
+
+func newBroker(t *testing.T, handlers map[string]sarama.MockResponse) (*sarama.MockBroker, *saramaadmin.Admins, string) {
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+
+	handlers["MetadataRequest"] = sarama.NewMockMetadataResponse(t).
+		SetController(broker.BrokerID()).
+		SetBroker(broker.Addr(), broker.BrokerID()).
+		SetLeader(topic, 0, broker.BrokerID()).
+		SetLeader(topic, 1, broker.BrokerID())
+	broker.SetHandlerByMap(handlers)
+
+	config := sarama.NewConfig()
+	// IncrementalAlterConfigs needs Kafka 2.3.
+	config.Version = sarama.V2_3_0_0
+	config.ApiVersionsRequest = false
+	config.Metadata.Retry.Max = 0
+
+	resolver := &saramaadmin.NameResolver{
+		Pools: map[string][]string{pool: {broker.Addr()}},
+	}
+	service, err := resolver.ResolveKafkaServiceName(context.Background(), pool)
+	require.NoError(t, err)
+
+	return broker, saramaadmin.NewAdmins(config), service
+}
+
+func requests[T any](broker *sarama.MockBroker) []T {
+	var out []T
+	for _, rr := range broker.History() {
+		if req, ok := rr.Request.(T); ok {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+func markers(resources ...sarama.Resource) *sarama.MockWrapper {
+	rsp := &sarama.DescribeAclsResponse{Version: 1}
+	for _, resource := range resources {
+		rsp.ResourceAcls = append(rsp.ResourceAcls, &sarama.ResourceAcls{
+			Resource: resource,
+			Acls: []*sarama.Acl{{
+				Principal:      "User:" + username,
+				Host:           "*",
+				Operation:      sarama.AclOperationDescribe,
+				PermissionType: sarama.AclPermissionAllow,
+			}},
+		})
+	}
+	return sarama.NewMockWrapper(rsp)
+}
+
+func marker(name string) sarama.Resource {
+	return sarama.Resource{
+		ResourceType:        sarama.AclResourceTransactionalID,
+		ResourceName:        name,
+		ResourcePatternType: sarama.AclPatternLiteral,
+	}
+}
+
+func TestParsePools(t *testing.T) {
+	pools, err := saramaadmin.ParsePools([]string{"local=kafka-0:9092", "local=kafka-1:9092", "other=localhost:9092"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"local": {"kafka-0:9092", "kafka-1:9092"},
+		"other": {"localhost:9092"},
+	}, pools)
+
+	resolver := &saramaadmin.NameResolver{Pools: pools}
+	service, err := resolver.ResolveKafkaServiceName(context.Background(), "local")
+	require.NoError(t, err)
+	assert.Equal(t, "kafka-0:9092,kafka-1:9092", service)
+
+	_, err = resolver.ResolveKafkaServiceName(context.Background(), "unknown")
+	assert.EqualError(t, err, "no kafka brokers configured for pool unknown")
+
+	_, err = saramaadmin.ParsePools([]string{"localhost:9092"})
+	assert.EqualError(t, err, "invalid pool brokers 'localhost:9092'; expected pool=host:port")
+}
+
+func TestTopicGet(t *testing.T) {
+	_, admins, service := newBroker(t, map[string]sarama.MockResponse{
+		"DescribeConfigsRequest": sarama.NewMockDescribeConfigsResponse(t),
+	})
+	client := &saramaadmin.TopicClient{Admins: admins}
+
+	kafkaTopic, err := client.Get(context.Background(), pool, service, topic)
+	require.NoError(t, err)
+
+	assert.Equal(t, topic, kafkaTopic.TopicName)
+	assert.Len(t, kafkaTopic.Partitions, 2)
+	assert.Equal(t, 1, kafkaTopic.Replication)
+	assert.Equal(t, int64(1000000), kafkaTopic.Config.MaxMessageBytes.Value)
+	assert.Equal(t, int64(5000), kafkaTopic.Config.RetentionMs.Value)
+	assert.Equal(t, int64(0), kafkaTopic.Config.SegmentMs.Value)
+
+	_, err = client.Get(context.Background(), pool, service, "myteam.unknown")
+	assert.True(t, aiven.IsNotFound(err))
+}
+
+func TestTopicCreate(t *testing.T) {
+	broker, admins, service := newBroker(t, map[string]sarama.MockResponse{
+		"CreateTopicsRequest": sarama.NewMockCreateTopicsResponse(t),
+	})
+	client := &saramaadmin.TopicClient{Admins: admins}
+
+	err := client.Create(context.Background(), pool, service, aiven.CreateKafkaTopicRequest{
+		TopicName:  topic,
+		Partitions: new(3),
+		Config: aiven.KafkaTopicConfig{
+			CleanupPolicy:               "compact",
+			CompressionType:             "zstd",
+			MessageDownconversionEnable: new(false),
+			RetentionMs:                 new(int64(3600000)),
+		},
+	})
+	require.NoError(t, err)
+
+	reqs := requests[*sarama.CreateTopicsRequest](broker)
+	require.Len(t, reqs, 1)
+	detail := reqs[0].TopicDetails[topic]
+	assert.Equal(t, int32(3), detail.NumPartitions)
+	assert.Equal(t, int16(-1), detail.ReplicationFactor)
+	assert.Equal(t, map[string]*string{
+		"cleanup.policy":                new("compact"),
+		"compression.type":              new("zstd"),
+		"message.downconversion.enable": new("false"),
+		"retention.ms":                  new("3600000"),
+	}, detail.ConfigEntries)
+}
+
+func TestTopicUpdate(t *testing.T) {
+	broker, admins, service := newBroker(t, map[string]sarama.MockResponse{
+		"IncrementalAlterConfigsRequest": sarama.NewMockIncrementalAlterConfigsResponse(t),
+	})
+	client := &saramaadmin.TopicClient{Admins: admins}
+
+	err := client.Update(context.Background(), pool, service, topic, aiven.UpdateKafkaTopicRequest{
+		Config: aiven.KafkaTopicConfig{RetentionMs: new(int64(3600000))},
+	})
+	require.NoError(t, err)
+
+	reqs := requests[*sarama.IncrementalAlterConfigsRequest](broker)
+	require.Len(t, reqs, 1)
+	assert.Equal(t, map[string]sarama.IncrementalAlterConfigsEntry{
+		"retention.ms": {Operation: sarama.IncrementalAlterConfigsOperationSet, Value: new("3600000")},
+	}, reqs[0].Resources[0].ConfigEntries)
+}
+
+func TestTopicTags(t *testing.T) {
+	_, admins, service := newBroker(t, map[string]sarama.MockResponse{
+		"DescribeConfigsRequest":         sarama.NewMockDescribeConfigsResponse(t),
+		"IncrementalAlterConfigsRequest": sarama.NewMockIncrementalAlterConfigsResponse(t),
+		"DeleteTopicsRequest":            sarama.NewMockDeleteTopicsResponse(t),
+	})
+	client := &saramaadmin.TopicClient{Admins: admins}
+	createdBy := aiven.KafkaTopicTag{Key: "created-by", Value: "Kafkarator"}
+	tags := []aiven.KafkaTopicTag{createdBy, {Key: "cluster", Value: "local"}, {Key: "synchronization-hash", Value: "abc123"}}
+
+	kafkaTopic, err := client.Get(context.Background(), pool, service, topic)
+	require.NoError(t, err)
+	assert.Equal(t, []aiven.KafkaTopicTag{createdBy}, kafkaTopic.Tags)
+
+	err = client.Update(context.Background(), pool, service, topic, aiven.UpdateKafkaTopicRequest{Tags: tags})
+	require.NoError(t, err)
+	kafkaTopic, err = client.Get(context.Background(), pool, service, topic)
+	require.NoError(t, err)
+	assert.Equal(t, tags, kafkaTopic.Tags)
+
+	err = client.Delete(context.Background(), pool, service, topic)
+	require.NoError(t, err)
+	kafkaTopic, err = client.Get(context.Background(), pool, service, topic)
+	require.NoError(t, err)
+	assert.Equal(t, []aiven.KafkaTopicTag{createdBy}, kafkaTopic.Tags)
+}
+
+func TestTopicTagsConfigMap(t *testing.T) {
+	ctx := context.Background()
+	_, admins, service := newBroker(t, map[string]sarama.MockResponse{
+		"CreateTopicsRequest":    sarama.NewMockCreateTopicsResponse(t),
+		"DescribeConfigsRequest": sarama.NewMockDescribeConfigsResponse(t),
+		"DeleteTopicsRequest":    sarama.NewMockDeleteTopicsResponse(t),
+	})
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	store := &saramaadmin.ConfigMapTagStore{Client: kubeClient, Reader: kubeClient, Namespace: "kafkarator"}
+	tags := []aiven.KafkaTopicTag{{Key: "created-by", Value: "Kafkarator"}, {Key: "cluster", Value: "local"}}
+
+	// Topics with unknown tags are not regarded as created by Kafkarator.
+	kafkaTopic, err := (&saramaadmin.TopicClient{Admins: admins, Tags: store}).Get(ctx, pool, service, topic)
+	require.NoError(t, err)
+	assert.Empty(t, kafkaTopic.Tags)
+
+	err = (&saramaadmin.TopicClient{Admins: admins, Tags: store}).Create(ctx, pool, service, aiven.CreateKafkaTopicRequest{TopicName: topic, Tags: tags})
+	require.NoError(t, err)
+
+	// The tags are kept by a new client, as after a restart.
+	kafkaTopic, err = (&saramaadmin.TopicClient{Admins: admins, Tags: store}).Get(ctx, pool, service, topic)
+	require.NoError(t, err)
+	assert.Equal(t, tags, kafkaTopic.Tags)
+
+	configMap := &corev1.ConfigMap{}
+	require.NoError(t, kubeClient.Get(ctx, client.ObjectKey{Namespace: "kafkarator", Name: "kafkarator-topic-tags-" + pool}, configMap))
+	assert.Equal(t, "true", configMap.Labels[saramaadmin.LabelTopicTags])
+
+	err = (&saramaadmin.TopicClient{Admins: admins, Tags: store}).Delete(ctx, pool, service, topic)
+	require.NoError(t, err)
+	kafkaTopic, err = (&saramaadmin.TopicClient{Admins: admins, Tags: store}).Get(ctx, pool, service, topic)
+	require.NoError(t, err)
+	assert.Empty(t, kafkaTopic.Tags)
+}
+
+func TestAclList(t *testing.T) {
+	_, admins, service := newBroker(t, map[string]sarama.MockResponse{
+		"DescribeAclsRequest": markers(
+			marker("kafkarator/read/"+topic),
+			marker("kafkarator/admin/myteam.mystream_stream_*"),
+			marker("some-transaction"),
+		),
+	})
+	client := &saramaadmin.AclClient{Admins: admins}
+
+	acls, err := client.List(context.Background(), pool, service)
+	require.NoError(t, err)
+	assert.Equal(t, []*acl.Acl{
+		{
+			ID:         "read/" + topic + "/" + username,
+			Permission: "read",
+			Topic:      topic,
+			Username:   username,
+		},
+		{
+			ID:         "admin/myteam.mystream_stream_*/" + username,
+			Permission: "admin",
+			Topic:      "myteam.mystream_stream_*",
+			Username:   username,
+		},
+	}, acls)
+}
+
+func TestAclCreate(t *testing.T) {
+	broker, admins, service := newBroker(t, map[string]sarama.MockResponse{
+		"CreateAclsRequest": sarama.NewMockCreateAclsResponse(t),
+	})
+	client := &saramaadmin.AclClient{Admins: admins}
+
+	created, err := client.Create(context.Background(), pool, service, acl.CreateKafkaACLRequest{
+		Permission: "readwrite",
+		Topic:      "myteam.mystream_stream_*",
+		Username:   username,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "readwrite/myteam.mystream_stream_*/"+username, created.ID)
+
+	reqs := requests[*sarama.CreateAclsRequest](broker)
+	require.Len(t, reqs, 1)
+
+	type binding struct {
+		resource  sarama.Resource
+		operation sarama.AclOperation
+	}
+	var bindings []binding
+	for _, creation := range reqs[0].AclCreations {
+		assert.Equal(t, "User:"+username, creation.Principal)
+		bindings = append(bindings, binding{creation.Resource, creation.Operation})
+	}
+	prefixed := sarama.Resource{
+		ResourceType:        sarama.AclResourceTopic,
+		ResourceName:        "myteam.mystream_stream_",
+		ResourcePatternType: sarama.AclPatternPrefixed,
+	}
+	assert.ElementsMatch(t, []binding{
+		{prefixed, sarama.AclOperationRead},
+		{prefixed, sarama.AclOperationWrite},
+		{marker("kafkarator/readwrite/myteam.mystream_stream_*"), sarama.AclOperationDescribe},
+	}, bindings)
+
+	_, err = client.Create(context.Background(), pool, service, acl.CreateKafkaACLRequest{
+		Permission: "superuser",
+		Topic:      topic,
+		Username:   username,
+	})
+	assert.EqualError(t, err, "unsupported ACL permission 'superuser'")
+}
+
+func TestAclDeleteKeepsOperationsGrantedByOtherEntries(t *testing.T) {
+	broker, admins, service := newBroker(t, map[string]sarama.MockResponse{
+		"DeleteAclsRequest":   sarama.NewMockDeleteAclsResponse(t),
+		"DescribeAclsRequest": markers(marker("kafkarator/read/" + topic)),
+	})
+	client := &saramaadmin.AclClient{Admins: admins}
+
+	err := client.Delete(context.Background(), pool, service, "readwrite/"+topic+"/"+username)
+	require.NoError(t, err)
+
+	reqs := requests[*sarama.DeleteAclsRequest](broker)
+	require.Len(t, reqs, 2)
+
+	markerFilter := reqs[0].Filters[0]
+	assert.Equal(t, sarama.AclResourceTransactionalID, markerFilter.ResourceType)
+	assert.Equal(t, "kafkarator/readwrite/"+topic, *markerFilter.ResourceName)
+
+	topicFilter := reqs[1].Filters[0]
+	assert.Equal(t, sarama.AclResourceTopic, topicFilter.ResourceType)
+	assert.Equal(t, topic, *topicFilter.ResourceName)
+	assert.Equal(t, sarama.AclOperationWrite, topicFilter.Operation)
+	assert.Equal(t, "User:"+username, *topicFilter.Principal)
+}
+
+func TestAclSecurityDisabled(t *testing.T) {
+	broker, admins, service := newBroker(t, map[string]sarama.MockResponse{
+		"CreateAclsRequest": sarama.NewMockWrapper(&sarama.CreateAclsResponse{
+			AclCreationResponses: []*sarama.AclCreationResponse{{Err: sarama.ErrSecurityDisabled}},
+		}),
+		"DeleteAclsRequest": sarama.NewMockWrapper(&sarama.DeleteAclsResponse{
+			FilterResponses: []*sarama.FilterResponse{{Err: sarama.ErrSecurityDisabled}},
+		}),
+		"DescribeAclsRequest": sarama.NewMockWrapper(&sarama.DescribeAclsResponse{Err: sarama.ErrSecurityDisabled}),
+	})
+	client := &saramaadmin.AclClient{Admins: admins}
+
+	acls, err := client.List(context.Background(), pool, service)
+	require.NoError(t, err)
+	assert.Empty(t, acls)
+
+	_, err = client.Create(context.Background(), pool, service, acl.CreateKafkaACLRequest{
+		Permission: "read",
+		Topic:      topic,
+		Username:   username,
+	})
+	require.NoError(t, err)
+
+	err = client.Delete(context.Background(), pool, service, "read/"+topic+"/"+username)
+	require.NoError(t, err)
+	assert.Len(t, requests[*sarama.DeleteAclsRequest](broker), 2)
+}
@@ -0,0 +1,158 @@
+package saramaadmin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/aiven/aiven-go-client/v2"
+	corev1 "k8s.io/api/core/v1"
+	apimachinery_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+)
+
+// Label and name prefix of the ConfigMaps that keep the tags of topics in local pools.
+const (
+	LabelTopicTags      = "kafkarator.nais.io/topic-tags"
+	topicTagsNamePrefix = "kafkarator-topic-tags-"
+)
+
+// TagStore keeps the tags of topics, since plain Kafka has no topic tags.
+type TagStore interface {
+	// Get returns the tags of a topic, or nil if the topic has none.
+	Get(ctx context.Context, pool, topicName string) ([]aiven.KafkaTopicTag, error)
+	// Set replaces the tags of a topic; nil tags forget them.
+	Set(ctx context.Context, pool, topicName string, tags []aiven.KafkaTopicTag) error
+}
+
+// memoryTagStore keeps tags in memory, and they are lost on restart.
+// Topics without known tags get the created-by tag, so that every topic in a local pool is regarded as managed
+// by Kafkarator; topics created by hand can not be told apart.
+type memoryTagStore struct {
+	lock sync.Mutex
+	tags map[string][]aiven.KafkaTopicTag
+}
+
+var _ TagStore = &memoryTagStore{}
+
+func (s *memoryTagStore) Get(_ context.Context, pool, topicName string) ([]aiven.KafkaTopicTag, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if tags, ok := s.tags[pool+"/"+topicName]; ok {
+		return slices.Clone(tags), nil
+	}
+	return []aiven.KafkaTopicTag{
+		{Key: topic.TagCreatedBy, Value: topic.CreatedByKafkarator},
+	}, nil
+}
+
+func (s *memoryTagStore) Set(_ context.Context, pool, topicName string, tags []aiven.KafkaTopicTag) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.tags == nil {
+		s.tags = make(map[string][]aiven.KafkaTopicTag)
+	}
+	if tags == nil {
+		delete(s.tags, pool+"/"+topicName)
+		return nil
+	}
+	s.tags[pool+"/"+topicName] = slices.Clone(tags)
+	return nil
+}
+
+// ConfigMapTagStore keeps the tags of the topics in each pool in a ConfigMap in one namespace, keyed by topic name.
+// Topics missing from the ConfigMap have no tags, and must be adopted like topics created by hand in Aiven.
+type ConfigMapTagStore struct {
+	Client client.Client
+	// Reader reads tags without a cache, so that ConfigMaps in the cluster are not cached.
+	Reader    client.Reader
+	Namespace string
+}
+
+var _ TagStore = &ConfigMapTagStore{}
+
+func (s *ConfigMapTagStore) key(pool string) client.ObjectKey {
+	return client.ObjectKey{Namespace: s.Namespace, Name: topicTagsNamePrefix + pool}
+}
+
+func (s *ConfigMapTagStore) Get(ctx context.Context, pool, topicName string) ([]aiven.KafkaTopicTag, error) {
+	configMap := &corev1.ConfigMap{}
+	err := s.Reader.Get(ctx, s.key(pool), configMap)
+	if apimachinery_errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get tags of topic %s: %w", topicName, err)
+	}
+	data, ok := configMap.Data[topicName]
+	if !ok {
+		return nil, nil
+	}
+	var tags []aiven.KafkaTopicTag
+	if err = json.Unmarshal([]byte(data), &tags); err != nil {
+		return nil, fmt.Errorf("parse tags of topic %s: %w", topicName, err)
+	}
+	return tags, nil
+}
+
+func (s *ConfigMapTagStore) Set(ctx context.Context, pool, topicName string, tags []aiven.KafkaTopicTag) error {
+	var data []byte
+	if tags != nil {
+		var err error
+		data, err = json.Marshal(tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Topics of the same pool are synchronized in parallel, and share the ConfigMap.
+	retriable := func(err error) bool {
+		return apimachinery_errors.IsConflict(err) || apimachinery_errors.IsAlreadyExists(err)
+	}
+	err := retry.OnError(retry.DefaultRetry, retriable, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := s.Reader.Get(ctx, s.key(pool), configMap)
+		if apimachinery_errors.IsNotFound(err) {
+			if tags == nil {
+				return nil
+			}
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.key(pool).Name,
+					Namespace: s.Namespace,
+					Labels: map[string]string{
+						LabelTopicTags: "true",
+					},
+				},
+				Data: map[string]string{topicName: string(data)},
+			}
+			return s.Client.Create(ctx, configMap)
+		}
+		if err != nil {
+			return err
+		}
+
+		if tags == nil {
+			if _, ok := configMap.Data[topicName]; !ok {
+				return nil
+			}
+			delete(configMap.Data, topicName)
+		} else {
+			if configMap.Data == nil {
+				configMap.Data = make(map[string]string)
+			}
+			configMap.Data[topicName] = string(data)
+		}
+		return s.Client.Update(ctx, configMap)
+	})
+	if err != nil {
+		return fmt.Errorf("store tags of topic %s: %w", topicName, err)
+	}
+	return nil
+}
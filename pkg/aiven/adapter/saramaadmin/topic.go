@@ -0,0 +1,370 @@
+package saramaadmin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+)
+
+// Kafka topic configuration names for the Aiven topic configuration fields managed by Kafkarator.
+const (
+	configCleanupPolicy                   = "cleanup.policy"
+	configCompressionType                 = "compression.type"
+	configDeleteRetentionMs               = "delete.retention.ms"
+	configIndexIntervalBytes              = "index.interval.bytes"
+	configLocalRetentionBytes             = "local.retention.bytes"
+	configLocalRetentionMs                = "local.retention.ms"
+	configMaxCompactionLagMs              = "max.compaction.lag.ms"
+	configMaxMessageBytes                 = "max.message.bytes"
+	configMessageDownconversionEnable     = "message.downconversion.enable"
+	configMessageTimestampAfterMaxMs      = "message.timestamp.after.max.ms"
+	configMessageTimestampBeforeMaxMs     = "message.timestamp.before.max.ms"
+	configMessageTimestampDifferenceMaxMs = "message.timestamp.difference.max.ms"
+	configMessageTimestampType            = "message.timestamp.type"
+	configMinCleanableDirtyRatio          = "min.cleanable.dirty.ratio"
+	configMinCompactionLagMs              = "min.compaction.lag.ms"
+	configMinInsyncReplicas               = "min.insync.replicas"
+	configRemoteStorageEnable             = "remote.storage.enable"
+	configRetentionBytes                  = "retention.bytes"
+	configRetentionMs                     = "retention.ms"
+	configSegmentBytes                    = "segment.bytes"
+	configSegmentJitterMs                 = "segment.jitter.ms"
+	configSegmentMs                       = "segment.ms"
+)
+
+type TopicClient struct {
+	Admins *Admins
+	// Tags keeps the tags of topics, since plain Kafka has no topic tags. Tags are kept in memory if nil.
+	Tags TagStore
+
+	memoryTags memoryTagStore
+}
+
+var _ topic.Interface = &TopicClient{}
+
+func notFound(topicName string) error {
+	return aiven.Error{
+		Message: fmt.Sprintf("Topic '%s' does not exist", topicName),
+		Status:  http.StatusNotFound,
+	}
+}
+
+func (c *TopicClient) tagStore() TagStore {
+	if c.Tags == nil {
+		return &c.memoryTags
+	}
+	return c.Tags
+}
+
+func (c *TopicClient) describe(admin sarama.ClusterAdmin, topicName string) (*sarama.TopicMetadata, error) {
+	metadata, err := admin.DescribeTopics([]string{topicName})
+	if err != nil {
+		return nil, err
+	}
+	if len(metadata) == 0 || errors.Is(metadata[0].Err, sarama.ErrUnknownTopicOrPartition) {
+		return nil, notFound(topicName)
+	}
+	if metadata[0].Err != sarama.ErrNoError {
+		return nil, metadata[0].Err
+	}
+	return metadata[0], nil
+}
+
+func (c *TopicClient) Get(ctx context.Context, project, service, topicName string) (*aiven.KafkaTopic, error) {
+	admin, err := c.Admins.Admin(service)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := c.describe(admin, topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{
+		Type: sarama.TopicResource,
+		Name: topicName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := c.tagStore().Get(ctx, project, topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	config := topicConfigResponse(entries)
+	partitions := make([]*aiven.Partition, 0, len(metadata.Partitions))
+	for _, partition := range metadata.Partitions {
+		partitions = append(partitions, &aiven.Partition{
+			Partition: int(partition.ID),
+			ISR:       len(partition.Isr),
+		})
+	}
+
+	return &aiven.KafkaTopic{
+		CleanupPolicy:         config.CleanupPolicy.Value,
+		MinimumInSyncReplicas: int(config.MinInsyncReplicas.Value),
+		Partitions:            partitions,
+		Replication:           replication(metadata),
+		RetentionBytes:        int(config.RetentionBytes.Value),
+		State:                 "ACTIVE",
+		TopicName:             topicName,
+		Config:                config,
+		Tags:                  tags,
+	}, nil
+}
+
+func (c *TopicClient) List(_ context.Context, _, service string) ([]*aiven.KafkaListTopic, error) {
+	admin, err := c.Admins.Admin(service)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := admin.ListTopics()
+	if err != nil {
+		return nil, err
+	}
+
+	topics := make([]*aiven.KafkaListTopic, 0, len(details))
+	for name, detail := range details {
+		// Internal topics are not listed by Aiven either.
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		topics = append(topics, &aiven.KafkaListTopic{
+			Partitions:  int(detail.NumPartitions),
+			Replication: int(detail.ReplicationFactor),
+			State:       "ACTIVE",
+			TopicName:   name,
+		})
+	}
+	slices.SortFunc(topics, func(a, b *aiven.KafkaListTopic) int {
+		return strings.Compare(a.TopicName, b.TopicName)
+	})
+	return topics, nil
+}
+
+// V2List returns the named topics that exist. Kafka describes the configuration of one topic at a time.
+func (c *TopicClient) V2List(ctx context.Context, project, service string, topicNames []string) ([]*aiven.KafkaTopic, error) {
+	topics := make([]*aiven.KafkaTopic, 0, len(topicNames))
+	for _, topicName := range topicNames {
+		kafkaTopic, err := c.Get(ctx, project, service, topicName)
+		if aiven.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, kafkaTopic)
+	}
+	return topics, nil
+}
+
+func (c *TopicClient) Create(ctx context.Context, project, service string, req aiven.CreateKafkaTopicRequest) error {
+	admin, err := c.Admins.Admin(service)
+	if err != nil {
+		return err
+	}
+
+	// -1 leaves partitions and replication to the broker defaults.
+	detail := &sarama.TopicDetail{
+		NumPartitions:     -1,
+		ReplicationFactor: -1,
+		ConfigEntries:     configEntries(req.Config),
+	}
+	if req.Partitions != nil {
+		detail.NumPartitions = int32(*req.Partitions)
+	}
+	if req.Replication != nil {
+		detail.ReplicationFactor = int16(*req.Replication)
+	}
+
+	err = admin.CreateTopic(req.TopicName, detail, false)
+	if err != nil {
+		return err
+	}
+	err = c.tagStore().Set(ctx, project, req.TopicName, req.Tags)
+	if err != nil {
+		// The new topic is empty, and is deleted so that it is not left without tags, which would need adoption.
+		if deleteErr := admin.DeleteTopic(req.TopicName); deleteErr != nil {
+			return fmt.Errorf("%w; delete topic without tags: %w", err, deleteErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Update sets the topic configuration and adds partitions if needed.
+// Kafka can not remove partitions, and changing the replication factor requires a partition reassignment,
+// so both are reported as errors.
+func (c *TopicClient) Update(ctx context.Context, project, service, topicName string, req aiven.UpdateKafkaTopicRequest) error {
+	admin, err := c.Admins.Admin(service)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := c.describe(admin, topicName)
+	if err != nil {
+		return err
+	}
+
+	if req.Replication != nil && *req.Replication != replication(metadata) {
+		return fmt.Errorf("changing replication of topic '%s' from %d to %d is not supported", topicName, replication(metadata), *req.Replication)
+	}
+
+	if req.Partitions != nil {
+		current := len(metadata.Partitions)
+		switch {
+		case *req.Partitions < current:
+			return fmt.Errorf("reducing partitions of topic '%s' from %d to %d is not supported", topicName, current, *req.Partitions)
+		case *req.Partitions > current:
+			err = admin.CreatePartitions(topicName, int32(*req.Partitions), nil, false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Only the settings in the request are changed, leaving other settings of the topic as they are.
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry)
+	for name, value := range configEntries(req.Config) {
+		entries[name] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationSet, Value: value}
+	}
+	err = admin.IncrementalAlterConfig(sarama.TopicResource, topicName, entries, false)
+	if err != nil {
+		return err
+	}
+	if req.Tags != nil {
+		return c.tagStore().Set(ctx, project, topicName, req.Tags)
+	}
+	return nil
+}
+
+func (c *TopicClient) Delete(ctx context.Context, project, service, topicName string) error {
+	admin, err := c.Admins.Admin(service)
+	if err != nil {
+		return err
+	}
+
+	err = admin.DeleteTopic(topicName)
+	if errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+		err = notFound(topicName)
+	}
+	if err == nil || aiven.IsNotFound(err) {
+		if tagErr := c.tagStore().Set(ctx, project, topicName, nil); tagErr != nil {
+			return tagErr
+		}
+	}
+	return err
+}
+
+func replication(metadata *sarama.TopicMetadata) int {
+	if len(metadata.Partitions) == 0 {
+		return 0
+	}
+	return len(metadata.Partitions[0].Replicas)
+}
+
+// configEntries converts the Aiven topic configuration to Kafka topic configuration entries.
+func configEntries(cfg aiven.KafkaTopicConfig) map[string]*string {
+	entries := make(map[string]*string)
+	setString := func(name string, value string) {
+		if len(value) > 0 {
+			entries[name] = new(value)
+		}
+	}
+	setInt := func(name string, value *int64) {
+		if value != nil {
+			entries[name] = new(strconv.FormatInt(*value, 10))
+		}
+	}
+	setBool := func(name string, value *bool) {
+		if value != nil {
+			entries[name] = new(strconv.FormatBool(*value))
+		}
+	}
+
+	setString(configCleanupPolicy, cfg.CleanupPolicy)
+	setString(configCompressionType, cfg.CompressionType)
+	setString(configMessageTimestampType, cfg.MessageTimestampType)
+	setInt(configDeleteRetentionMs, cfg.DeleteRetentionMs)
+	setInt(configIndexIntervalBytes, cfg.IndexIntervalBytes)
+	setInt(configLocalRetentionBytes, cfg.LocalRetentionBytes)
+	setInt(configLocalRetentionMs, cfg.LocalRetentionMs)
+	setInt(configMaxCompactionLagMs, cfg.MaxCompactionLagMs)
+	setInt(configMaxMessageBytes, cfg.MaxMessageBytes)
+	setInt(configMessageTimestampAfterMaxMs, cfg.MessageTimestampAfterMaxMs)
+	setInt(configMessageTimestampBeforeMaxMs, cfg.MessageTimestampBeforeMaxMs)
+	setInt(configMessageTimestampDifferenceMaxMs, cfg.MessageTimestampDifferenceMaxMs)
+	setInt(configMinCompactionLagMs, cfg.MinCompactionLagMs)
+	setInt(configMinInsyncReplicas, cfg.MinInsyncReplicas)
+	setInt(configRetentionBytes, cfg.RetentionBytes)
+	setInt(configRetentionMs, cfg.RetentionMs)
+	setInt(configSegmentBytes, cfg.SegmentBytes)
+	setInt(configSegmentJitterMs, cfg.SegmentJitterMs)
+	setInt(configSegmentMs, cfg.SegmentMs)
+	if cfg.MinCleanableDirtyRatio != nil {
+		entries[configMinCleanableDirtyRatio] = new(strconv.FormatFloat(*cfg.MinCleanableDirtyRatio, 'f', -1, 64))
+	}
+	setBool(configMessageDownconversionEnable, cfg.MessageDownconversionEnable)
+	setBool(configRemoteStorageEnable, cfg.RemoteStorageEnable)
+
+	return entries
+}
+
+// topicConfigResponse converts Kafka topic configuration entries to the Aiven topic configuration.
+// Every field compared by the topic synchronization is set, using zero values for configuration unknown to the broker.
+func topicConfigResponse(entries []sarama.ConfigEntry) aiven.KafkaTopicConfigResponse {
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		values[entry.Name] = entry.Value
+	}
+
+	stringValue := func(name string) *aiven.KafkaTopicConfigResponseString {
+		return &aiven.KafkaTopicConfigResponseString{Value: values[name]}
+	}
+	intValue := func(name string) *aiven.KafkaTopicConfigResponseInt {
+		value, _ := strconv.ParseInt(values[name], 10, 64)
+		return &aiven.KafkaTopicConfigResponseInt{Value: value}
+	}
+	boolValue := func(name string) *aiven.KafkaTopicConfigResponseBool {
+		value, _ := strconv.ParseBool(values[name])
+		return &aiven.KafkaTopicConfigResponseBool{Value: value}
+	}
+	floatValue, _ := strconv.ParseFloat(values[configMinCleanableDirtyRatio], 64)
+
+	return aiven.KafkaTopicConfigResponse{
+		CleanupPolicy:                   stringValue(configCleanupPolicy),
+		CompressionType:                 stringValue(configCompressionType),
+		DeleteRetentionMs:               intValue(configDeleteRetentionMs),
+		IndexIntervalBytes:              intValue(configIndexIntervalBytes),
+		LocalRetentionBytes:             intValue(configLocalRetentionBytes),
+		LocalRetentionMs:                intValue(configLocalRetentionMs),
+		MaxCompactionLagMs:              intValue(configMaxCompactionLagMs),
+		MaxMessageBytes:                 intValue(configMaxMessageBytes),
+		MessageDownconversionEnable:     boolValue(configMessageDownconversionEnable),
+		MessageTimestampAfterMaxMs:      intValue(configMessageTimestampAfterMaxMs),
+		MessageTimestampBeforeMaxMs:     intValue(configMessageTimestampBeforeMaxMs),
+		MessageTimestampDifferenceMaxMs: intValue(configMessageTimestampDifferenceMaxMs),
+		MessageTimestampType:            stringValue(configMessageTimestampType),
+		MinCleanableDirtyRatio:          &aiven.KafkaTopicConfigResponseFloat{Value: floatValue},
+		MinCompactionLagMs:              intValue(configMinCompactionLagMs),
+		MinInsyncReplicas:               intValue(configMinInsyncReplicas),
+		RemoteStorageEnable:             boolValue(configRemoteStorageEnable),
+		RetentionBytes:                  intValue(configRetentionBytes),
+		RetentionMs:                     intValue(configRetentionMs),
+		SegmentBytes:                    intValue(configSegmentBytes),
+		SegmentJitterMs:                 intValue(configSegmentJitterMs),
+		SegmentMs:                       intValue(configSegmentMs),
+	}
+}
@@ -2,6 +2,7 @@ package kafkarator_aiven
 
 import (
 	"github.com/nais/kafkarator/pkg/aiven/acl"
+	"github.com/nais/kafkarator/pkg/aiven/schema"
 	"github.com/nais/kafkarator/pkg/aiven/topic"
 	"github.com/nais/liberator/pkg/aiven/service"
 )
@@ -10,4 +11,12 @@ type Interfaces struct {
 	ACLs         acl.Interface
 	Topics       topic.Interface
 	NameResolver service.NameResolver
+	// Pools maps pool names to explicit project and service pairs; see ResolvePool.
+	Pools map[string]Pool
+	// SchemaRegistryACLs is optional; schema registry ACLs are only managed when it is set.
+	SchemaRegistryACLs acl.SchemaRegistryInterface
+	// Schemas is optional; topics can only declare schemas when it is set.
+	Schemas schema.Interface
+	// Capabilities is optional; topics are only checked against the capabilities of their pool when it is set.
+	Capabilities *CapabilitiesCache
 }
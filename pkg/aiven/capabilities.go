@@ -0,0 +1,112 @@
+package kafkarator_aiven
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	"github.com/nais/kafkarator/pkg/metrics"
+	"github.com/nais/liberator/pkg/aiven/service"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+)
+
+// Capabilities of the Kafka service of a pool, that topics must fit within.
+type Capabilities struct {
+	Pool      string
+	Plan      string
+	NodeCount int
+	// MessageMaxBytes is the largest message accepted by the service, or zero if not set in the service configuration.
+	MessageMaxBytes int
+	TieredStorage   bool
+}
+
+// CapabilitiesOf reads the capabilities of a pool from its Aiven service.
+func CapabilitiesOf(pool string, svc *aiven.Service) Capabilities {
+	capabilities := Capabilities{
+		Pool:      pool,
+		Plan:      svc.Plan,
+		NodeCount: svc.NodeCount,
+	}
+	// Numbers in the user config are decoded from JSON.
+	if kafka, ok := svc.UserConfig["kafka"].(map[string]any); ok {
+		if maxBytes, ok := kafka["message_max_bytes"].(float64); ok {
+			capabilities.MessageMaxBytes = int(maxBytes)
+		}
+	}
+	if tieredStorage, ok := svc.UserConfig["tiered_storage"].(map[string]any); ok {
+		capabilities.TieredStorage, _ = tieredStorage["enabled"].(bool)
+	}
+	return capabilities
+}
+
+// Check returns an error if the topic configuration needs more than the service of the pool provides.
+func (c Capabilities) Check(cfg *kafka_nais_io_v1.Config) error {
+	if cfg.Replication != nil && c.NodeCount > 0 && *cfg.Replication > c.NodeCount {
+		return fmt.Errorf("replication (%d) exceeds the %d nodes of the Kafka service of pool '%s'", *cfg.Replication, c.NodeCount, c.Pool)
+	}
+	if cfg.MaxMessageBytes != nil && c.MessageMaxBytes > 0 && *cfg.MaxMessageBytes > c.MessageMaxBytes {
+		return fmt.Errorf("maxMessageBytes (%d) exceeds the message_max_bytes of the Kafka service of pool '%s' (%d)", *cfg.MaxMessageBytes, c.Pool, c.MessageMaxBytes)
+	}
+	if topic.RemoteStorage(cfg) && !c.TieredStorage {
+		return fmt.Errorf("local retention requires tiered storage, which is not enabled for the Kafka service of pool '%s' (plan %s)", c.Pool, c.Plan)
+	}
+	return nil
+}
+
+type cachedCapabilities struct {
+	capabilities Capabilities
+	fetched      time.Time
+}
+
+// CapabilitiesCache reads the capabilities of pools from their Aiven service, and caches them for TTL.
+type CapabilitiesCache struct {
+	Services service.Interface
+	TTL      time.Duration
+
+	lock  sync.Mutex
+	cache map[Pool]cachedCapabilities
+}
+
+func NewCapabilitiesCache(services service.Interface, ttl time.Duration) *CapabilitiesCache {
+	return &CapabilitiesCache{
+		Services: services,
+		TTL:      ttl,
+		cache:    make(map[Pool]cachedCapabilities),
+	}
+}
+
+// Get returns the capabilities of a pool, reading its service from Aiven if not cached.
+func (c *CapabilitiesCache) Get(ctx context.Context, pool Pool) (Capabilities, error) {
+	c.lock.Lock()
+	cached, ok := c.cache[pool]
+	c.lock.Unlock()
+	if ok && time.Since(cached.fetched) < c.TTL {
+		return cached.capabilities, nil
+	}
+
+	var svc *aiven.Service
+	err := metrics.ObserveAivenLatency("Service_Get", pool.Project, func() error {
+		var err error
+		svc, err = c.Services.Get(ctx, pool.Project, pool.Service)
+		return err
+	})
+	if err != nil {
+		return Capabilities{}, err
+	}
+	return c.Update(pool, svc), nil
+}
+
+// Update caches the capabilities of a pool from its service, i.e. when the service is read for other purposes.
+func (c *CapabilitiesCache) Update(pool Pool, svc *aiven.Service) Capabilities {
+	capabilities := CapabilitiesOf(pool.Name, svc)
+	c.lock.Lock()
+	c.cache[pool] = cachedCapabilities{
+		capabilities: capabilities,
+		fetched:      time.Now(),
+	}
+	c.lock.Unlock()
+	return capabilities
+}
@@ -0,0 +1,56 @@
+package kafkarator_aiven_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/liberator/pkg/aiven/service"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
+)
+
+func TestCapabilities(t *testing.T) {
+	svc := &aiven.Service{Plan: "business-4", NodeCount: 3}
+	// The user config is decoded from JSON, as returned by Aiven.
+	err := json.Unmarshal([]byte(`{"kafka": {"message_max_bytes": 2097152}, "tiered_storage": {"enabled": false}}`), &svc.UserConfig)
+	require.NoError(t, err)
+
+	capabilities := kafkarator_aiven.CapabilitiesOf("nav-dev", svc)
+	assert.Equal(t, kafkarator_aiven.Capabilities{Pool: "nav-dev", Plan: "business-4", NodeCount: 3, MessageMaxBytes: 2097152}, capabilities)
+
+	assert.NoError(t, capabilities.Check(&kafka_nais_io_v1.Config{Replication: new(3), MaxMessageBytes: new(2097152)}))
+	assert.EqualError(t, capabilities.Check(&kafka_nais_io_v1.Config{Replication: new(4)}), "replication (4) exceeds the 3 nodes of the Kafka service of pool 'nav-dev'")
+	assert.EqualError(t, capabilities.Check(&kafka_nais_io_v1.Config{MaxMessageBytes: new(4194304)}), "maxMessageBytes (4194304) exceeds the message_max_bytes of the Kafka service of pool 'nav-dev' (2097152)")
+	assert.EqualError(t, capabilities.Check(&kafka_nais_io_v1.Config{LocalRetentionHours: new(24)}), "local retention requires tiered storage, which is not enabled for the Kafka service of pool 'nav-dev' (plan business-4)")
+
+	capabilities.TieredStorage = true
+	assert.NoError(t, capabilities.Check(&kafka_nais_io_v1.Config{LocalRetentionHours: new(24)}))
+}
+
+func TestCapabilitiesCache(t *testing.T) {
+	ctx := context.Background()
+	pool := kafkarator_aiven.Pool{Name: "nav-dev", Project: "nav-dev", Service: "nav-dev-kafka"}
+	services := service.NewMockInterface(t)
+	services.On("Get", ctx, "nav-dev", "nav-dev-kafka").Return(&aiven.Service{Plan: "business-4", NodeCount: 3}, nil).Once()
+
+	cache := kafkarator_aiven.NewCapabilitiesCache(services, time.Hour)
+	capabilities, err := cache.Get(ctx, pool)
+	require.NoError(t, err)
+	assert.Equal(t, 3, capabilities.NodeCount)
+
+	// Cached until refreshed.
+	capabilities, err = cache.Get(ctx, pool)
+	require.NoError(t, err)
+	assert.Equal(t, 3, capabilities.NodeCount)
+
+	cache.Update(pool, &aiven.Service{Plan: "business-8", NodeCount: 6})
+	capabilities, err = cache.Get(ctx, pool)
+	require.NoError(t, err)
+	assert.Equal(t, 6, capabilities.NodeCount)
+}
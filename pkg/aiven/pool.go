@@ -0,0 +1,131 @@
+package kafkarator_aiven
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nais/liberator/pkg/aiven/service"
+)
+
+// Pool is the Kafka service that a logical pool name, as used in Topic.Spec.Pool, resolves to.
+type Pool struct {
+	Name    string
+	Project string
+	Service string
+}
+
+type PoolResolver interface {
+	ResolvePool(ctx context.Context, pool string) (Pool, error)
+	InvalidatePool(pool Pool)
+}
+
+// ResolvePool resolves a pool to an explicitly configured project and service.
+// Pools without explicit configuration resolve to the Kafka service of the project with the same name.
+func (a Interfaces) ResolvePool(ctx context.Context, pool string) (Pool, error) {
+	if p, ok := a.Pools[pool]; ok {
+		return p, nil
+	}
+
+	serviceName, err := a.NameResolver.ResolveKafkaServiceName(ctx, pool)
+	if err != nil {
+		return Pool{}, err
+	}
+	return Pool{
+		Name:    pool,
+		Project: pool,
+		Service: serviceName,
+	}, nil
+}
+
+// InvalidatePool makes the next ResolvePool look up the service of an implicitly resolved pool again,
+// for use when the resolved service no longer exists.
+func (a Interfaces) InvalidatePool(pool Pool) {
+	if _, ok := a.Pools[pool.Name]; ok {
+		return
+	}
+	if resolver, ok := a.NameResolver.(interface{ Invalidate(project string) }); ok {
+		resolver.Invalidate(pool.Project)
+	}
+}
+
+var _ PoolResolver = Interfaces{}
+
+// ParsePools parses a list of pool mappings on the form pool=project/service.
+func ParsePools(entries []string) (map[string]Pool, error) {
+	pools := make(map[string]Pool, len(entries))
+	for _, entry := range entries {
+		name, target, _ := strings.Cut(entry, "=")
+		project, serviceName, _ := strings.Cut(target, "/")
+		if len(name) == 0 || len(project) == 0 || len(serviceName) == 0 {
+			return nil, fmt.Errorf("invalid pool mapping '%s'; expected pool=project/service", entry)
+		}
+		if _, exists := pools[name]; exists {
+			return nil, fmt.Errorf("pool '%s' is mapped more than once", name)
+		}
+		pools[name] = Pool{
+			Name:    name,
+			Project: project,
+			Service: serviceName,
+		}
+	}
+	return pools, nil
+}
+
+type cachedServiceName struct {
+	name     string
+	resolved time.Time
+}
+
+// ExpiringNameResolver resolves the Kafka service of a project, and caches the result for TTL.
+// Expired or invalidated entries are resolved again, so that renamed services are picked up.
+type ExpiringNameResolver struct {
+	Services service.Interface
+	TTL      time.Duration
+
+	lock  sync.Mutex
+	cache map[string]cachedServiceName
+}
+
+func NewExpiringNameResolver(services service.Interface, ttl time.Duration) *ExpiringNameResolver {
+	return &ExpiringNameResolver{
+		Services: services,
+		TTL:      ttl,
+		cache:    make(map[string]cachedServiceName),
+	}
+}
+
+func (r *ExpiringNameResolver) ResolveKafkaServiceName(ctx context.Context, project string) (string, error) {
+	r.lock.Lock()
+	cached, ok := r.cache[project]
+	r.lock.Unlock()
+	if ok && time.Since(cached.resolved) < r.TTL {
+		return cached.name, nil
+	}
+
+	// The liberator resolver knows the naming conventions for Kafka services,
+	// but caches forever; use a fresh one for every lookup.
+	name, err := service.NewCachedNameResolver(r.Services).ResolveKafkaServiceName(ctx, project)
+	if err != nil {
+		return "", err
+	}
+
+	r.lock.Lock()
+	r.cache[project] = cachedServiceName{
+		name:     name,
+		resolved: time.Now(),
+	}
+	r.lock.Unlock()
+	return name, nil
+}
+
+// Invalidate forgets the cached service name of a project.
+func (r *ExpiringNameResolver) Invalidate(project string) {
+	r.lock.Lock()
+	delete(r.cache, project)
+	r.lock.Unlock()
+}
+
+var _ service.NameResolver = &ExpiringNameResolver{}
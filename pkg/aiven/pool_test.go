@@ -0,0 +1,75 @@
+package kafkarator_aiven_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/liberator/pkg/aiven/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
+)
+
+func TestParsePools(t *testing.T) {
+	pools, err := kafkarator_aiven.ParsePools([]string{"nav-fast=nav-prod/kafka-fast"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]kafkarator_aiven.Pool{
+		"nav-fast": {Name: "nav-fast", Project: "nav-prod", Service: "kafka-fast"},
+	}, pools)
+
+	_, err = kafkarator_aiven.ParsePools([]string{"nav-fast=nav-prod"})
+	assert.EqualError(t, err, "invalid pool mapping 'nav-fast=nav-prod'; expected pool=project/service")
+
+	_, err = kafkarator_aiven.ParsePools([]string{"nav-fast=nav-prod/a", "nav-fast=nav-prod/b"})
+	assert.EqualError(t, err, "pool 'nav-fast' is mapped more than once")
+}
+
+func TestResolvePool(t *testing.T) {
+	ctx := context.Background()
+	nameResolver := service.NewMockNameResolver(t)
+	nameResolver.On("ResolveKafkaServiceName", ctx, "nav-dev").Return("nav-dev-kafka", nil).Once()
+
+	interfaces := kafkarator_aiven.Interfaces{
+		NameResolver: nameResolver,
+		Pools: map[string]kafkarator_aiven.Pool{
+			"nav-fast": {Name: "nav-fast", Project: "nav-prod", Service: "kafka-fast"},
+		},
+	}
+
+	pool, err := interfaces.ResolvePool(ctx, "nav-fast")
+	require.NoError(t, err)
+	assert.Equal(t, kafkarator_aiven.Pool{Name: "nav-fast", Project: "nav-prod", Service: "kafka-fast"}, pool)
+
+	pool, err = interfaces.ResolvePool(ctx, "nav-dev")
+	require.NoError(t, err)
+	assert.Equal(t, kafkarator_aiven.Pool{Name: "nav-dev", Project: "nav-dev", Service: "nav-dev-kafka"}, pool)
+}
+
+func TestExpiringNameResolverResolvesAgainWhenInvalidated(t *testing.T) {
+	ctx := context.Background()
+	services := service.NewMockInterface(t)
+	services.On("Get", mock.Anything, "nav-dev", "kafka").Return(&aiven.Service{Name: "kafka"}, nil).Once()
+	services.On("Get", mock.Anything, "nav-dev", "kafka").Return(nil, aiven.Error{Status: 404}).Once()
+	services.On("Get", mock.Anything, "nav-dev", "nav-dev-kafka").Return(&aiven.Service{Name: "nav-dev-kafka"}, nil).Once()
+
+	resolver := kafkarator_aiven.NewExpiringNameResolver(services, time.Hour)
+	interfaces := kafkarator_aiven.Interfaces{NameResolver: resolver}
+
+	pool, err := interfaces.ResolvePool(ctx, "nav-dev")
+	require.NoError(t, err)
+	assert.Equal(t, "kafka", pool.Service)
+
+	// cached
+	pool, err = interfaces.ResolvePool(ctx, "nav-dev")
+	require.NoError(t, err)
+	assert.Equal(t, "kafka", pool.Service)
+
+	interfaces.InvalidatePool(pool)
+	pool, err = interfaces.ResolvePool(ctx, "nav-dev")
+	require.NoError(t, err)
+	assert.Equal(t, "nav-dev-kafka", pool.Service)
+}
@@ -0,0 +1,218 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// Keys used in the ConfigMap that holds the schemas for a topic.
+const (
+	ConfigMapKeySchema       = "key"
+	ConfigMapKeySchemaType   = "keyType"
+	ConfigMapValueSchema     = "value"
+	ConfigMapValueSchemaType = "valueType"
+	ConfigMapCompatibility   = "compatibility"
+)
+
+var (
+	schemaTypes         = []string{"AVRO", "JSON", "PROTOBUF"}
+	compatibilityLevels = []string{"BACKWARD", "BACKWARD_TRANSITIVE", "FORWARD", "FORWARD_TRANSITIVE", "FULL", "FULL_TRANSITIVE", "NONE"}
+)
+
+type Interface interface {
+	Add(ctx context.Context, project, service, name string, subject aiven.KafkaSchemaSubject) (*aiven.KafkaSchemaSubjectResponse, error)
+	GetVersions(ctx context.Context, project, service, name string) (*aiven.KafkaSchemaSubjectVersionsResponse, error)
+	Get(ctx context.Context, project, service, name string, version int) (*aiven.KafkaSchemaSubjectVersionResponse, error)
+	GetConfiguration(ctx context.Context, project, service, subjectName string) (*aiven.KafkaSchemaConfigResponse, error)
+	UpdateConfiguration(ctx context.Context, project, service, subjectName, compatibility string) (*aiven.KafkaSchemaConfigUpdateResponse, error)
+}
+
+var _ Interface = &aiven.KafkaSubjectSchemasHandler{}
+
+// Definition is a schema to be registered under a subject.
+type Definition struct {
+	Subject    string
+	Schema     string
+	SchemaType string
+}
+
+// Spec holds the schemas declared for a topic, and the compatibility level enforced on their subjects.
+type Spec struct {
+	Compatibility string
+	Definitions   []Definition
+}
+
+// Registered describes the schema that a subject resolved to after registration.
+type Registered struct {
+	ID      int `json:"id"`
+	Version int `json:"version,omitempty"`
+}
+
+// FromConfigMap parses the data of a schema ConfigMap into a Spec for the key and value subjects of a topic.
+func FromConfigMap(topic string, data map[string]string) (*Spec, error) {
+	spec := &Spec{
+		Compatibility: strings.ToUpper(strings.TrimSpace(data[ConfigMapCompatibility])),
+	}
+	if len(spec.Compatibility) > 0 && !slices.Contains(compatibilityLevels, spec.Compatibility) {
+		return nil, fmt.Errorf("invalid schema compatibility level '%s'; must be one of %v", spec.Compatibility, compatibilityLevels)
+	}
+
+	subjects := []struct {
+		schemaKey string
+		typeKey   string
+		subject   string
+	}{
+		{ConfigMapKeySchema, ConfigMapKeySchemaType, topic + "-key"},
+		{ConfigMapValueSchema, ConfigMapValueSchemaType, topic + "-value"},
+	}
+
+	for _, s := range subjects {
+		content, ok := data[s.schemaKey]
+		if !ok {
+			continue
+		}
+		schemaType := strings.ToUpper(strings.TrimSpace(data[s.typeKey]))
+		if len(schemaType) == 0 {
+			schemaType = "AVRO"
+		}
+		if !slices.Contains(schemaTypes, schemaType) {
+			return nil, fmt.Errorf("invalid schema type '%s' for subject %s; must be one of %v", schemaType, s.subject, schemaTypes)
+		}
+		spec.Definitions = append(spec.Definitions, Definition{
+			Subject:    s.subject,
+			Schema:     content,
+			SchemaType: schemaType,
+		})
+	}
+
+	if len(spec.Definitions) == 0 {
+		return nil, fmt.Errorf("schema ConfigMap must contain at least one of the keys '%s' or '%s'", ConfigMapKeySchema, ConfigMapValueSchema)
+	}
+
+	return spec, nil
+}
+
+type Manager struct {
+	AivenSchemas Interface
+	Project      string
+	Service      string
+	Logger       log.FieldLogger
+	DryRun       bool
+}
+
+// Synchronize registers every schema in the spec under its subject, after setting the subject compatibility level.
+// Aiven validates new schemas against the latest registered version, so incompatible schemas are returned as errors.
+func (r *Manager) Synchronize(ctx context.Context, spec Spec) (map[string]Registered, error) {
+	registered := make(map[string]Registered, len(spec.Definitions))
+
+	for _, def := range spec.Definitions {
+		logger := r.Logger.WithField("schema_subject", def.Subject)
+
+		if len(spec.Compatibility) > 0 {
+			err := r.setCompatibility(ctx, def.Subject, spec.Compatibility, logger)
+			if err != nil {
+				return nil, fmt.Errorf("set compatibility level for subject %s: %w", def.Subject, err)
+			}
+		}
+
+		if r.DryRun {
+			logger.Infof("DRY RUN: Would register %s schema", def.SchemaType)
+			continue
+		}
+
+		var rsp *aiven.KafkaSchemaSubjectResponse
+		err := metrics.ObserveAivenLatency("Schema_Add", r.Project, func() error {
+			var err error
+			rsp, err = r.AivenSchemas.Add(ctx, r.Project, r.Service, def.Subject, aiven.KafkaSchemaSubject{
+				Schema:     def.Schema,
+				SchemaType: def.SchemaType,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("schema for subject %s rejected by schema registry: %w", def.Subject, err)
+		}
+
+		reg := Registered{ID: rsp.Id}
+		version, err := r.latestVersion(ctx, def.Subject, rsp.Id)
+		if err != nil {
+			logger.Warnf("Unable to look up version of registered schema: %s", err)
+		} else {
+			reg.Version = version
+		}
+
+		registered[def.Subject] = reg
+		logger.WithFields(log.Fields{
+			"schema_id":      reg.ID,
+			"schema_version": reg.Version,
+		}).Infof("Registered schema")
+	}
+
+	return registered, nil
+}
+
+func (r *Manager) setCompatibility(ctx context.Context, subject, compatibility string, logger log.FieldLogger) error {
+	var current *aiven.KafkaSchemaConfigResponse
+	err := metrics.ObserveAivenLatency("Schema_GetConfiguration", r.Project, func() error {
+		var err error
+		current, err = r.AivenSchemas.GetConfiguration(ctx, r.Project, r.Service, subject)
+		return err
+	})
+	if err != nil && !aiven.IsNotFound(err) {
+		return err
+	}
+	if err == nil && current.CompatibilityLevel == compatibility {
+		return nil
+	}
+
+	return metrics.ObserveAivenLatency("Schema_UpdateConfiguration", r.Project, func() error {
+		if r.DryRun {
+			logger.Infof("DRY RUN: Would set compatibility level %s", compatibility)
+			return nil
+		}
+		_, err := r.AivenSchemas.UpdateConfiguration(ctx, r.Project, r.Service, subject, compatibility)
+		if err == nil {
+			logger.Infof("Set compatibility level %s", compatibility)
+		}
+		return err
+	})
+}
+
+// latestVersion returns the latest version of a subject, if it is the version holding the schema with the given id.
+// Registering a schema that is identical to an older version returns the id of that version,
+// in which case the version is reported as unknown (zero).
+func (r *Manager) latestVersion(ctx context.Context, subject string, id int) (int, error) {
+	var versions *aiven.KafkaSchemaSubjectVersionsResponse
+	err := metrics.ObserveAivenLatency("Schema_GetVersions", r.Project, func() error {
+		var err error
+		versions, err = r.AivenSchemas.GetVersions(ctx, r.Project, r.Service, subject)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(versions.Versions) == 0 {
+		return 0, nil
+	}
+
+	latest := slices.Max(versions.Versions)
+	var version *aiven.KafkaSchemaSubjectVersionResponse
+	err = metrics.ObserveAivenLatency("Schema_Get", r.Project, func() error {
+		var err error
+		version, err = r.AivenSchemas.Get(ctx, r.Project, r.Service, subject, latest)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if version.Version.Id != id {
+		return 0, nil
+	}
+	return latest, nil
+}
@@ -0,0 +1,114 @@
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aiven/aiven-go-client/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/nais/kafkarator/pkg/aiven/schema"
+)
+
+const (
+	project = "mypool"
+	service = "kafka"
+	topic   = "myteam.mytopic"
+)
+
+func TestFromConfigMap(t *testing.T) {
+	spec, err := schema.FromConfigMap(topic, map[string]string{
+		"value":         `{"type": "string"}`,
+		"key":           `{"type": "object"}`,
+		"keyType":       "json",
+		"compatibility": "full_transitive",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &schema.Spec{
+		Compatibility: "FULL_TRANSITIVE",
+		Definitions: []schema.Definition{
+			{Subject: topic + "-key", Schema: `{"type": "object"}`, SchemaType: "JSON"},
+			{Subject: topic + "-value", Schema: `{"type": "string"}`, SchemaType: "AVRO"},
+		},
+	}, spec)
+
+	_, err = schema.FromConfigMap(topic, map[string]string{"compatibility": "BACKWARD"})
+	assert.ErrorContains(t, err, "at least one of the keys")
+
+	_, err = schema.FromConfigMap(topic, map[string]string{"value": "{}", "compatibility": "SOMETIMES"})
+	assert.ErrorContains(t, err, "invalid schema compatibility level")
+
+	_, err = schema.FromConfigMap(topic, map[string]string{"value": "{}", "valueType": "XML"})
+	assert.ErrorContains(t, err, "invalid schema type")
+}
+
+func TestManager_Synchronize(t *testing.T) {
+	ctx := context.Background()
+	subject := topic + "-value"
+	spec := schema.Spec{
+		Compatibility: "BACKWARD",
+		Definitions: []schema.Definition{
+			{Subject: subject, Schema: `{"type": "string"}`, SchemaType: "AVRO"},
+		},
+	}
+
+	m := schema.NewMockInterface(t)
+	m.On("GetConfiguration", ctx, project, service, subject).
+		Return(nil, aiven.Error{Status: http.StatusNotFound})
+	m.On("UpdateConfiguration", ctx, project, service, subject, "BACKWARD").
+		Return(&aiven.KafkaSchemaConfigUpdateResponse{}, nil)
+	m.On("Add", ctx, project, service, subject, aiven.KafkaSchemaSubject{Schema: `{"type": "string"}`, SchemaType: "AVRO"}).
+		Return(&aiven.KafkaSchemaSubjectResponse{Id: 42}, nil)
+	m.On("GetVersions", ctx, project, service, subject).
+		Return(&aiven.KafkaSchemaSubjectVersionsResponse{
+			KafkaSchemaSubjectVersions: aiven.KafkaSchemaSubjectVersions{Versions: []int{1, 3, 2}},
+		}, nil)
+	m.On("Get", ctx, project, service, subject, 3).
+		Return(&aiven.KafkaSchemaSubjectVersionResponse{
+			Version: aiven.KafkaSchemaSubjectVersion{Id: 42, Version: 3},
+		}, nil)
+
+	manager := schema.Manager{
+		AivenSchemas: m,
+		Project:      project,
+		Service:      service,
+		Logger:       log.New(),
+	}
+
+	registered, err := manager.Synchronize(ctx, spec)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]schema.Registered{
+		subject: {ID: 42, Version: 3},
+	}, registered)
+}
+
+func TestManager_SynchronizeRejected(t *testing.T) {
+	ctx := context.Background()
+	subject := topic + "-value"
+	spec := schema.Spec{
+		Compatibility: "BACKWARD",
+		Definitions: []schema.Definition{
+			{Subject: subject, Schema: `{"type": "int"}`, SchemaType: "AVRO"},
+		},
+	}
+
+	m := schema.NewMockInterface(t)
+	m.On("GetConfiguration", ctx, project, service, subject).
+		Return(&aiven.KafkaSchemaConfigResponse{CompatibilityLevel: "BACKWARD"}, nil)
+	m.On("Add", ctx, project, service, subject, mock.Anything).
+		Return(nil, errors.New("kafka schema is not compatible with version :3"))
+
+	manager := schema.Manager{
+		AivenSchemas: m,
+		Project:      project,
+		Service:      service,
+		Logger:       log.New(),
+	}
+
+	_, err := manager.Synchronize(ctx, spec)
+	assert.ErrorContains(t, err, "rejected by schema registry: kafka schema is not compatible with version :3")
+}
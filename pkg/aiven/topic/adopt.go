@@ -0,0 +1,21 @@
+package topic
+
+import (
+	"context"
+
+	"github.com/aiven/aiven-go-client/v2"
+)
+
+// Unmanaged returns the topic in Aiven if it exists without being tagged by Kafkarator, such as topics created by hand,
+// together with the differences between the topic and the spec. Synchronizing the topic tags it as managed.
+func (r *Manager) Unmanaged(ctx context.Context) (*aiven.KafkaTopic, []string, error) {
+	topic, err := r.get(ctx)
+	if err != nil || topic == nil || managed(topic) {
+		return nil, nil, err
+	}
+	return topic, topicConfigDifferences(topic, r.Topic.Spec.Config, r.ExtraConfig), nil
+}
+
+func managed(topic *aiven.KafkaTopic) bool {
+	return TagValue(topic.Tags, TagCreatedBy) == CreatedByKafkarator
+}
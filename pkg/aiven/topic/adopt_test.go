@@ -0,0 +1,37 @@
+package topic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+)
+
+func TestUnmanaged(t *testing.T) {
+	ctx := context.Background()
+	existing := &aiven.KafkaTopic{
+		Replication: 2,
+		Config: aiven.KafkaTopicConfigResponse{
+			CompressionType: &aiven.KafkaTopicConfigResponseString{Value: "producer"},
+		},
+	}
+
+	m := topic.NewMockInterface(t)
+	m.On("Get", ctx, "adoptproject", "kafka", "myteam.mytopic").Return(existing, nil).Once()
+	manager := taggedManager(m, "adoptproject", topic.Ownership{})
+	manager.ExtraConfig = topic.ExtraConfig{"compression.type": "zstd"}
+
+	unmanaged, differences, err := manager.Unmanaged(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, existing, unmanaged)
+	assert.Equal(t, []string{"compression.type: producer -> zstd", "replication: 2 -> 3"}, differences)
+
+	existing.Tags = []aiven.KafkaTopicTag{{Key: topic.TagCreatedBy, Value: "Kafkarator"}}
+	unmanaged, differences, err = manager.Unmanaged(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, unmanaged)
+	assert.Empty(t, differences)
+}
@@ -0,0 +1,85 @@
+package topic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+)
+
+// DataLossError is returned when synchronizing the topic deletes data in Aiven, and the change is not acknowledged.
+type DataLossError struct {
+	Topic string
+	// Hash is the synchronization hash a change must be acknowledged with.
+	Hash    string
+	Changes []string
+}
+
+func (e *DataLossError) Error() string {
+	return fmt.Sprintf("changing topic '%s' deletes data: %s", e.Topic, strings.Join(e.Changes, "; "))
+}
+
+// CheckDataLoss returns a DataLossError if the spec changes the topic in Aiven in a way that deletes data,
+// unless the change is acknowledged with the synchronization hash of the spec.
+func (r *Manager) CheckDataLoss(ctx context.Context) error {
+	topic, err := r.get(ctx)
+	if err != nil || topic == nil {
+		return err
+	}
+	changes := dataLoss(topic, r.Topic.Spec.Config)
+	if len(changes) == 0 {
+		return nil
+	}
+	if len(r.Ownership.Hash) > 0 && r.AcknowledgedDataLoss == r.Ownership.Hash {
+		r.Logger.Warnf("Applying acknowledged changes that delete data: %s", strings.Join(changes, "; "))
+		return nil
+	}
+	return &DataLossError{Topic: r.Topic.FullName(), Hash: r.Ownership.Hash, Changes: changes}
+}
+
+// dataLoss describes the changes from the topic in Aiven to the spec that make Kafka delete data:
+// lower retention, and compaction of a topic that was not compacted.
+func dataLoss(topic *aiven.KafkaTopic, cfg *kafka_nais_io_v1.Config) []string {
+	if cfg == nil {
+		return nil
+	}
+	var changes []string
+
+	// Negative values mean unlimited retention.
+	lowered := func(current *aiven.KafkaTopicConfigResponseInt, wanted *int64) bool {
+		return current != nil && wanted != nil && *wanted >= 0 && (current.Value < 0 || *wanted < current.Value)
+	}
+	unlimited := func(value int64, format func(int64) string) string {
+		if value < 0 {
+			return "unlimited"
+		}
+		return format(value)
+	}
+	duration := func(ms int64) string {
+		return (time.Duration(ms) * time.Millisecond).String()
+	}
+	bytes := func(b int64) string {
+		return fmt.Sprintf("%d bytes", b)
+	}
+
+	current := topic.Config
+	if wanted := retentionMs(cfg.RetentionHours, retentionHourDefault); lowered(current.RetentionMs, wanted) {
+		changes = append(changes, fmt.Sprintf("retention.ms is lowered from %s to %s, deleting records older than %[2]s",
+			unlimited(current.RetentionMs.Value, duration), duration(*wanted)))
+	}
+	if wanted := intpToInt64p(cfg.RetentionBytes); lowered(current.RetentionBytes, wanted) {
+		changes = append(changes, fmt.Sprintf("retention.bytes is lowered from %s to %s, deleting the oldest records of larger partitions",
+			unlimited(current.RetentionBytes.Value, bytes), bytes(*wanted)))
+	}
+	if current.CleanupPolicy != nil {
+		wanted := cleanupPolicy(cfg)
+		if strings.Contains(wanted, "compact") && !strings.Contains(current.CleanupPolicy.Value, "compact") {
+			changes = append(changes, fmt.Sprintf("cleanup.policy is changed from %s to %s, deleting all but the latest record of each key",
+				current.CleanupPolicy.Value, wanted))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,110 @@
+package topic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aiven/aiven-go-client/v2"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+)
+
+func TestCheckDataLoss(t *testing.T) {
+	ctx := context.Background()
+	existing := &aiven.KafkaTopic{
+		Replication: 3,
+		Config: aiven.KafkaTopicConfigResponse{
+			CleanupPolicy:  &aiven.KafkaTopicConfigResponseString{Value: "delete"},
+			RetentionBytes: &aiven.KafkaTopicConfigResponseInt{Value: 1000},
+			RetentionMs:    &aiven.KafkaTopicConfigResponseInt{Value: -1},
+		},
+	}
+
+	for _, test := range []struct {
+		name         string
+		config       kafka_nais_io_v1.Config
+		acknowledged string
+		changes      []string
+	}{
+		{
+			name:   "unchanged",
+			config: kafka_nais_io_v1.Config{CleanupPolicy: new("delete"), RetentionBytes: new(1000)},
+		},
+		{
+			name:   "higher retention",
+			config: kafka_nais_io_v1.Config{RetentionBytes: new(2000), RetentionHours: new(-1)},
+		},
+		{
+			name:   "compaction added to deletion",
+			config: kafka_nais_io_v1.Config{CleanupPolicy: new("compact,delete")},
+			changes: []string{
+				"cleanup.policy is changed from delete to compact,delete, deleting all but the latest record of each key",
+			},
+		},
+		{
+			name:   "lower retention",
+			config: kafka_nais_io_v1.Config{RetentionBytes: new(500), RetentionHours: new(72)},
+			changes: []string{
+				"retention.ms is lowered from unlimited to 72h0m0s, deleting records older than 72h0m0s",
+				"retention.bytes is lowered from 1000 bytes to 500 bytes, deleting the oldest records of larger partitions",
+			},
+		},
+		{
+			name:         "acknowledged",
+			config:       kafka_nais_io_v1.Config{RetentionBytes: new(500)},
+			acknowledged: "abc123",
+		},
+		{
+			name:         "acknowledged for another spec",
+			config:       kafka_nais_io_v1.Config{RetentionBytes: new(500)},
+			acknowledged: "def456",
+			changes: []string{
+				"retention.bytes is lowered from 1000 bytes to 500 bytes, deleting the oldest records of larger partitions",
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			m := topic.NewMockInterface(t)
+			m.On("Get", ctx, "datalossproject", "kafka", "myteam.mytopic").Return(existing, nil).Once()
+			manager := taggedManager(m, "datalossproject", topic.Ownership{Hash: "abc123"})
+			manager.Topic.Spec.Config = &test.config
+			manager.AcknowledgedDataLoss = test.acknowledged
+
+			err := manager.CheckDataLoss(ctx)
+			if len(test.changes) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, &topic.DataLossError{Topic: "myteam.mytopic", Hash: "abc123", Changes: test.changes}, err)
+		})
+	}
+}
+
+func TestAcknowledgedCleanupPolicyChangeIsApplied(t *testing.T) {
+	ctx := context.Background()
+	existing := &aiven.KafkaTopic{
+		Replication: 3,
+		Config: aiven.KafkaTopicConfigResponse{
+			CleanupPolicy: &aiven.KafkaTopicConfigResponseString{Value: "delete"},
+		},
+		Tags: []aiven.KafkaTopicTag{
+			{Key: topic.TagCreatedBy, Value: "Kafkarator"},
+			{Key: topic.TagCluster, Value: "dev-gcp"},
+		},
+	}
+	m := topic.NewMockInterface(t)
+	m.On("Get", ctx, "cleanupproject", "kafka", "myteam.mytopic").Return(existing, nil).Once()
+	m.On("Update", ctx, "cleanupproject", "kafka", "myteam.mytopic", mock.MatchedBy(func(req aiven.UpdateKafkaTopicRequest) bool {
+		return req.Config.CleanupPolicy == "compact"
+	})).Return(nil).Once()
+
+	manager := taggedManager(m, "cleanupproject", topic.Ownership{Cluster: "dev-gcp", Hash: "abc123"})
+	manager.Topic.Spec.Config.CleanupPolicy = new("compact")
+	manager.AcknowledgedDataLoss = "abc123"
+
+	assert.NoError(t, manager.CheckDataLoss(ctx))
+	assert.NoError(t, manager.Synchronize(ctx))
+}
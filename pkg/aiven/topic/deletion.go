@@ -0,0 +1,48 @@
+package topic
+
+import (
+	"context"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/metrics"
+)
+
+// TagDeleteAfter is set on topics whose data is scheduled for deletion, with the time the data may be deleted.
+// Synchronizing the topic again removes the tag, which cancels the deletion.
+const TagDeleteAfter = "delete-after"
+
+// ScheduleDeletion tags the topic with the time its data may be deleted, keeping the configuration and the other tags.
+// The topic is claimed by this cluster, so that it is deleted by this cluster. Returns false if the topic does not exist.
+func (r *Manager) ScheduleDeletion(ctx context.Context, deleteAfter time.Time) (bool, error) {
+	topic, err := r.get(ctx)
+	if err != nil || topic == nil {
+		return false, err
+	}
+
+	tags := make([]aiven.KafkaTopicTag, 0, len(topic.Tags)+2)
+	for _, tag := range topic.Tags {
+		if tag.Key != TagDeleteAfter && tag.Key != TagCluster {
+			tags = append(tags, tag)
+		}
+	}
+	if len(r.Ownership.Cluster) > 0 {
+		tags = append(tags, aiven.KafkaTopicTag{Key: TagCluster, Value: r.Ownership.Cluster})
+	}
+	tags = append(tags, aiven.KafkaTopicTag{Key: TagDeleteAfter, Value: deleteAfter.UTC().Format(time.RFC3339)})
+
+	return true, metrics.ObserveAivenLatency("Topic_Update", r.Project, func() error {
+		if r.DryRun {
+			r.Logger.Infof("DRY RUN: Would schedule deletion of Topic at %s", deleteAfter)
+			return nil
+		}
+		return r.AivenTopics.Update(ctx, r.Project, r.Service, r.Topic.FullName(), aiven.UpdateKafkaTopicRequest{Tags: tags})
+	})
+}
+
+// DeletionDeadline returns the time the data of a topic may be deleted, if the topic is scheduled for deletion.
+// Topics with an unreadable deadline are not regarded as scheduled.
+func DeletionDeadline(topic *aiven.KafkaTopic) (time.Time, bool) {
+	deadline, err := time.Parse(time.RFC3339, TagValue(topic.Tags, TagDeleteAfter))
+	return deadline, err == nil
+}
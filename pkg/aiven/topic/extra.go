@@ -0,0 +1,221 @@
+package topic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/ghodss/yaml"
+)
+
+// ExtraConfig holds topic configuration not modelled in the Topic spec, keyed by the Kafka configuration name,
+// such as compression.type. Only the settings in ExtraConfigNames are allowed.
+type ExtraConfig map[string]string
+
+type extraSetting struct {
+	// allowed values of string settings, or nil for numeric and boolean settings
+	allowed []string
+	boolean bool
+	min     int64
+	set     func(cfg *aiven.KafkaTopicConfig, value string)
+	current func(cfg aiven.KafkaTopicConfigResponse) (string, bool)
+}
+
+func stringSetting(allowed []string, set func(*aiven.KafkaTopicConfig, string), current func(aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseString) extraSetting {
+	return extraSetting{
+		allowed: allowed,
+		set:     set,
+		current: func(cfg aiven.KafkaTopicConfigResponse) (string, bool) {
+			value := current(cfg)
+			if value == nil {
+				return "", false
+			}
+			return value.Value, true
+		},
+	}
+}
+
+func intSetting(minimum int64, field func(*aiven.KafkaTopicConfig) **int64, current func(aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseInt) extraSetting {
+	return extraSetting{
+		min: minimum,
+		set: func(cfg *aiven.KafkaTopicConfig, value string) {
+			number, _ := strconv.ParseInt(value, 10, 64)
+			*field(cfg) = &number
+		},
+		current: func(cfg aiven.KafkaTopicConfigResponse) (string, bool) {
+			value := current(cfg)
+			if value == nil {
+				return "", false
+			}
+			return strconv.FormatInt(value.Value, 10), true
+		},
+	}
+}
+
+func boolSetting(field func(*aiven.KafkaTopicConfig) **bool, current func(aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseBool) extraSetting {
+	return extraSetting{
+		boolean: true,
+		set: func(cfg *aiven.KafkaTopicConfig, value string) {
+			b, _ := strconv.ParseBool(value)
+			*field(cfg) = &b
+		},
+		current: func(cfg aiven.KafkaTopicConfigResponse) (string, bool) {
+			value := current(cfg)
+			if value == nil {
+				return "", false
+			}
+			return strconv.FormatBool(value.Value), true
+		},
+	}
+}
+
+// extraSettings is the allowlist of extra configuration, with the values accepted by Aiven for each setting.
+var extraSettings = map[string]extraSetting{
+	"compression.type": stringSetting(
+		[]string{"gzip", "lz4", "producer", "snappy", "uncompressed", "zstd"},
+		func(cfg *aiven.KafkaTopicConfig, value string) { cfg.CompressionType = value },
+		func(cfg aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseString {
+			return cfg.CompressionType
+		},
+	),
+	"message.timestamp.type": stringSetting(
+		[]string{"CreateTime", "LogAppendTime"},
+		func(cfg *aiven.KafkaTopicConfig, value string) { cfg.MessageTimestampType = value },
+		func(cfg aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseString {
+			return cfg.MessageTimestampType
+		},
+	),
+	"message.downconversion.enable": boolSetting(
+		func(cfg *aiven.KafkaTopicConfig) **bool { return &cfg.MessageDownconversionEnable },
+		func(cfg aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseBool {
+			return cfg.MessageDownconversionEnable
+		},
+	),
+	"message.timestamp.after.max.ms": intSetting(0,
+		func(cfg *aiven.KafkaTopicConfig) **int64 { return &cfg.MessageTimestampAfterMaxMs },
+		func(cfg aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseInt {
+			return cfg.MessageTimestampAfterMaxMs
+		},
+	),
+	"message.timestamp.before.max.ms": intSetting(0,
+		func(cfg *aiven.KafkaTopicConfig) **int64 { return &cfg.MessageTimestampBeforeMaxMs },
+		func(cfg aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseInt {
+			return cfg.MessageTimestampBeforeMaxMs
+		},
+	),
+	"message.timestamp.difference.max.ms": intSetting(0,
+		func(cfg *aiven.KafkaTopicConfig) **int64 { return &cfg.MessageTimestampDifferenceMaxMs },
+		func(cfg aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseInt {
+			return cfg.MessageTimestampDifferenceMaxMs
+		},
+	),
+	"segment.bytes": intSetting(14,
+		func(cfg *aiven.KafkaTopicConfig) **int64 { return &cfg.SegmentBytes },
+		func(cfg aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseInt { return cfg.SegmentBytes },
+	),
+	"segment.jitter.ms": intSetting(0,
+		func(cfg *aiven.KafkaTopicConfig) **int64 { return &cfg.SegmentJitterMs },
+		func(cfg aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseInt {
+			return cfg.SegmentJitterMs
+		},
+	),
+	"index.interval.bytes": intSetting(0,
+		func(cfg *aiven.KafkaTopicConfig) **int64 { return &cfg.IndexIntervalBytes },
+		func(cfg aiven.KafkaTopicConfigResponse) *aiven.KafkaTopicConfigResponseInt {
+			return cfg.IndexIntervalBytes
+		},
+	),
+}
+
+// ExtraConfigNames lists the allowed extra configuration settings.
+func ExtraConfigNames() []string {
+	return slices.Sorted(maps.Keys(extraSettings))
+}
+
+// ParseExtraConfig reads extra configuration in YAML or JSON, and validates every setting against the allowlist.
+// Numbers and booleans are normalized, so that they compare equal to the configuration read from Aiven.
+func ParseExtraConfig(data string) (ExtraConfig, error) {
+	jsonData, err := yaml.YAMLToJSON([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse extra topic configuration: %w", err)
+	}
+	values := make(map[string]any)
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	if err = decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("parse extra topic configuration: %w", err)
+	}
+
+	extra := make(ExtraConfig, len(values))
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		var value string
+		switch v := values[name].(type) {
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		case bool:
+			value = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("topic configuration '%s' must be a string, number or boolean", name)
+		}
+		extra[name], err = normalizeExtra(name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return extra, nil
+}
+
+func normalizeExtra(name, value string) (string, error) {
+	setting, ok := extraSettings[name]
+	if !ok {
+		return "", fmt.Errorf("topic configuration '%s' is not supported; supported settings are %s", name, strings.Join(ExtraConfigNames(), ", "))
+	}
+	switch {
+	case setting.allowed != nil:
+		if !slices.Contains(setting.allowed, value) {
+			return "", fmt.Errorf("topic configuration '%s' must be one of %s", name, strings.Join(setting.allowed, ", "))
+		}
+		return value, nil
+	case setting.boolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("topic configuration '%s' must be true or false", name)
+		}
+		return strconv.FormatBool(b), nil
+	default:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || number < setting.min {
+			return "", fmt.Errorf("topic configuration '%s' must be a number of at least %d", name, setting.min)
+		}
+		return strconv.FormatInt(number, 10), nil
+	}
+}
+
+// apply sets the extra configuration in an Aiven topic configuration. The configuration must be parsed by ParseExtraConfig.
+func (e ExtraConfig) apply(cfg *aiven.KafkaTopicConfig) {
+	for name, value := range e {
+		extraSettings[name].set(cfg, value)
+	}
+}
+
+// differences describes every extra setting that differs from the topic configuration in Aiven.
+func (e ExtraConfig) differences(cfg aiven.KafkaTopicConfigResponse) []string {
+	var differences []string
+	for _, name := range slices.Sorted(maps.Keys(e)) {
+		current, ok := extraSettings[name].current(cfg)
+		if !ok {
+			current = "unset"
+		}
+		if !ok || current != e[name] {
+			differences = append(differences, fmt.Sprintf("%s: %s -> %s", name, current, e[name]))
+		}
+	}
+	return differences
+}
@@ -0,0 +1,63 @@
+package topic_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+)
+
+func TestParseExtraConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		data     string
+		expected topic.ExtraConfig
+		err      string
+	}{
+		{
+			name: "normalized values",
+			data: "compression.type: zstd\nsegment.bytes: 01048576\nmessage.downconversion.enable: TRUE",
+			expected: topic.ExtraConfig{
+				"compression.type":              "zstd",
+				"segment.bytes":                 "1048576",
+				"message.downconversion.enable": "true",
+			},
+		},
+		{
+			name:     "json",
+			data:     `{"message.timestamp.type": "LogAppendTime", "segment.jitter.ms": 60000}`,
+			expected: topic.ExtraConfig{"message.timestamp.type": "LogAppendTime", "segment.jitter.ms": "60000"},
+		},
+		{
+			name: "not allowlisted",
+			data: "unclean.leader.election.enable: true",
+			err:  "topic configuration 'unclean.leader.election.enable' is not supported; supported settings are compression.type, index.interval.bytes, message.downconversion.enable, message.timestamp.after.max.ms, message.timestamp.before.max.ms, message.timestamp.difference.max.ms, message.timestamp.type, segment.bytes, segment.jitter.ms",
+		},
+		{
+			name: "unknown value",
+			data: "compression.type: brotli",
+			err:  "topic configuration 'compression.type' must be one of gzip, lz4, producer, snappy, uncompressed, zstd",
+		},
+		{
+			name: "below minimum",
+			data: "segment.bytes: 10",
+			err:  "topic configuration 'segment.bytes' must be a number of at least 14",
+		},
+		{
+			name: "not a number",
+			data: "segment.jitter.ms: 1.5",
+			err:  "topic configuration 'segment.jitter.ms' must be a number of at least 0",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			extra, err := topic.ParseExtraConfig(tt.data)
+			if len(tt.err) > 0 {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, extra)
+		})
+	}
+}
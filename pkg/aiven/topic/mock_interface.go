@@ -404,3 +404,83 @@ func (_c *MockInterface_Update_Call) RunAndReturn(run func(ctx context.Context,
 	_c.Call.Return(run)
 	return _c
 }
+
+// V2List provides a mock function for the type MockInterface
+func (_mock *MockInterface) V2List(ctx context.Context, project string, service string, topics []string) ([]*aiven.KafkaTopic, error) {
+	ret := _mock.Called(ctx, project, service, topics)
+
+	if len(ret) == 0 {
+		panic("no return value specified for V2List")
+	}
+
+	var r0 []*aiven.KafkaTopic
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, []string) ([]*aiven.KafkaTopic, error)); ok {
+		return returnFunc(ctx, project, service, topics)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, []string) []*aiven.KafkaTopic); ok {
+		r0 = returnFunc(ctx, project, service, topics)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*aiven.KafkaTopic)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, []string) error); ok {
+		r1 = returnFunc(ctx, project, service, topics)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockInterface_V2List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'V2List'
+type MockInterface_V2List_Call struct {
+	*mock.Call
+}
+
+// V2List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - project string
+//   - service string
+//   - topics []string
+func (_e *MockInterface_Expecter) V2List(ctx interface{}, project interface{}, service interface{}, topics interface{}) *MockInterface_V2List_Call {
+	return &MockInterface_V2List_Call{Call: _e.mock.On("V2List", ctx, project, service, topics)}
+}
+
+func (_c *MockInterface_V2List_Call) Run(run func(ctx context.Context, project string, service string, topics []string)) *MockInterface_V2List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 []string
+		if args[3] != nil {
+			arg3 = args[3].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockInterface_V2List_Call) Return(kafkaV2ListTopics []*aiven.KafkaTopic, err error) *MockInterface_V2List_Call {
+	_c.Call.Return(kafkaV2ListTopics, err)
+	return _c
+}
+
+func (_c *MockInterface_V2List_Call) RunAndReturn(run func(ctx context.Context, project string, service string, topics []string) ([]*aiven.KafkaTopic, error)) *MockInterface_V2List_Call {
+	_c.Call.Return(run)
+	return _c
+}
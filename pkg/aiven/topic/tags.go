@@ -0,0 +1,123 @@
+package topic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Tags written to topics in Aiven, identifying the resource managing the topic.
+const (
+	TagCreatedBy  = "created-by"
+	TagTouchedAt  = "touched-at"
+	TagCluster    = "cluster"
+	TagNamespace  = "namespace"
+	TagName       = "name"
+	TagUID        = "uid"
+	TagGeneration = "generation"
+	TagHash       = "synchronization-hash"
+
+	// CreatedByKafkarator is the value of the TagCreatedBy tag on topics managed by Kafkarator.
+	CreatedByKafkarator = "Kafkarator"
+)
+
+// Ownership identifies the cluster managing a topic, and the version of the topic spec written to Aiven.
+type Ownership struct {
+	Cluster string
+	Hash    string
+	// Takeover allows claiming topics owned by another cluster.
+	Takeover bool
+}
+
+// OwnershipConflictError is returned when a topic in Aiven is owned by another cluster.
+type OwnershipConflictError struct {
+	Topic string
+	Owner string
+}
+
+func (e *OwnershipConflictError) Error() string {
+	return fmt.Sprintf("topic '%s' is owned by cluster '%s'", e.Topic, e.Owner)
+}
+
+// CheckOwnership returns an OwnershipConflictError if the topic exists in Aiven, and is owned by another cluster.
+// Topics without an owner, and topics in clusters without a name, are never in conflict.
+func (r *Manager) CheckOwnership(ctx context.Context) error {
+	topic, err := r.get(ctx)
+	if err != nil || topic == nil {
+		return err
+	}
+	owner := TagValue(topic.Tags, TagCluster)
+	if len(owner) == 0 || len(r.Ownership.Cluster) == 0 || owner == r.Ownership.Cluster {
+		return nil
+	}
+
+	// The check is done both before and during synchronization; only count it once.
+	if !r.checked {
+		r.checked = true
+		metrics.TopicTagMismatch.With(prometheus.Labels{
+			metrics.LabelPool:   r.Project,
+			metrics.LabelReason: metrics.ReasonOtherCluster,
+		}).Inc()
+		if r.Ownership.Takeover {
+			r.Logger.Warnf("Taking over topic owned by cluster '%s'", owner)
+		}
+	}
+	if r.Ownership.Takeover {
+		return nil
+	}
+	return &OwnershipConflictError{Topic: r.Topic.FullName(), Owner: owner}
+}
+
+// unclaimed returns true if the topic is not tagged as owned by this cluster.
+func (r *Manager) unclaimed(topic *aiven.KafkaTopic) bool {
+	return len(r.Ownership.Cluster) > 0 && TagValue(topic.Tags, TagCluster) != r.Ownership.Cluster
+}
+
+// tags returns the tags written on create and update. Tags without a value are left out.
+func (r *Manager) tags() []aiven.KafkaTopicTag {
+	tags := []aiven.KafkaTopicTag{
+		{Key: TagCreatedBy, Value: CreatedByKafkarator},
+		{Key: TagTouchedAt, Value: time.Now().Format(time.RFC3339)},
+	}
+	add := func(key, value string) {
+		if len(value) > 0 {
+			tags = append(tags, aiven.KafkaTopicTag{Key: key, Value: value})
+		}
+	}
+	add(TagCluster, r.Ownership.Cluster)
+	add(TagNamespace, r.Topic.Namespace)
+	add(TagName, r.Topic.Name)
+	add(TagUID, string(r.Topic.UID))
+	if r.Topic.Generation > 0 {
+		add(TagGeneration, strconv.FormatInt(r.Topic.Generation, 10))
+	}
+	add(TagHash, r.Ownership.Hash)
+	return tags
+}
+
+// TagValue returns the value of a tag, or an empty string if the tag is not set.
+func TagValue(tags []aiven.KafkaTopicTag, key string) string {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+// checkTags reports topics changed outside Kafkarator since they were last synchronized with the current spec.
+func (r *Manager) checkTags(topic *aiven.KafkaTopic, configChanged bool) {
+	hash := TagValue(topic.Tags, TagHash)
+	if configChanged && len(hash) > 0 && hash == r.Ownership.Hash {
+		r.Logger.Warnf("Topic configuration was changed outside Kafkarator; reverting to the configuration in the cluster")
+		metrics.TopicTagMismatch.With(prometheus.Labels{
+			metrics.LabelPool:   r.Project,
+			metrics.LabelReason: metrics.ReasonOutOfBand,
+		}).Inc()
+	}
+}
@@ -0,0 +1,101 @@
+package topic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aiven/aiven-go-client/v2"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	"github.com/nais/kafkarator/pkg/metrics"
+)
+
+func taggedManager(m topic.Interface, project string, ownership topic.Ownership) topic.Manager {
+	return topic.Manager{
+		AivenTopics: m,
+		Project:     project,
+		Service:     "kafka",
+		Topic: kafka_nais_io_v1.Topic{
+			ObjectMeta: metav1.ObjectMeta{Name: "mytopic", Namespace: "myteam"},
+			Spec: kafka_nais_io_v1.TopicSpec{
+				Config: &kafka_nais_io_v1.Config{Replication: new(3)},
+			},
+		},
+		Ownership: ownership,
+		Logger:    log.NewEntry(log.StandardLogger()),
+	}
+}
+
+func TestTagMismatch(t *testing.T) {
+	ctx := context.Background()
+	outOfBand := metrics.TopicTagMismatch.WithLabelValues("tagproject", metrics.ReasonOutOfBand)
+
+	existing := &aiven.KafkaTopic{
+		Replication: 2,
+		Tags: []aiven.KafkaTopicTag{
+			{Key: topic.TagCluster, Value: "dev-gcp"},
+			{Key: topic.TagHash, Value: "abc123"},
+		},
+	}
+	m := topic.NewMockInterface(t)
+	m.On("Get", ctx, "tagproject", "kafka", "myteam.mytopic").Return(existing, nil).Once()
+	m.On("Update", ctx, "tagproject", "kafka", "myteam.mytopic", mock.Anything).Return(nil)
+
+	manager := taggedManager(m, "tagproject", topic.Ownership{Cluster: "dev-gcp", Hash: "abc123"})
+	assert.NoError(t, manager.Synchronize(ctx))
+	assert.Equal(t, 1.0, testutil.ToFloat64(outOfBand))
+}
+
+func TestOwnership(t *testing.T) {
+	ctx := context.Background()
+	owned := func(cluster string) *aiven.KafkaTopic {
+		return &aiven.KafkaTopic{
+			Replication: 3,
+			Tags: []aiven.KafkaTopicTag{
+				{Key: topic.TagCreatedBy, Value: "Kafkarator"},
+				{Key: topic.TagCluster, Value: cluster},
+			},
+		}
+	}
+
+	t.Run("topic owned by another cluster", func(t *testing.T) {
+		otherCluster := metrics.TopicTagMismatch.WithLabelValues("conflictproject", metrics.ReasonOtherCluster)
+		m := topic.NewMockInterface(t)
+		m.On("Get", ctx, "conflictproject", "kafka", "myteam.mytopic").Return(owned("prod-gcp"), nil).Once()
+
+		manager := taggedManager(m, "conflictproject", topic.Ownership{Cluster: "dev-gcp"})
+		err := manager.Synchronize(ctx)
+		var conflict *topic.OwnershipConflictError
+		assert.ErrorAs(t, err, &conflict)
+		assert.EqualError(t, err, "topic 'myteam.mytopic' is owned by cluster 'prod-gcp'")
+		assert.Equal(t, 1.0, testutil.ToFloat64(otherCluster))
+	})
+
+	t.Run("takeover claims the topic", func(t *testing.T) {
+		otherCluster := metrics.TopicTagMismatch.WithLabelValues("takeoverproject", metrics.ReasonOtherCluster)
+		m := topic.NewMockInterface(t)
+		m.On("Get", ctx, "takeoverproject", "kafka", "myteam.mytopic").Return(owned("prod-gcp"), nil).Once()
+		m.On("Update", ctx, "takeoverproject", "kafka", "myteam.mytopic", mock.MatchedBy(func(req aiven.UpdateKafkaTopicRequest) bool {
+			return topic.TagValue(req.Tags, topic.TagCluster) == "dev-gcp"
+		})).Return(nil).Once()
+
+		manager := taggedManager(m, "takeoverproject", topic.Ownership{Cluster: "dev-gcp", Takeover: true})
+		assert.NoError(t, manager.CheckOwnership(ctx))
+		assert.NoError(t, manager.Synchronize(ctx))
+		assert.Equal(t, 1.0, testutil.ToFloat64(otherCluster))
+	})
+
+	t.Run("topic owned by this cluster", func(t *testing.T) {
+		m := topic.NewMockInterface(t)
+		m.On("Get", ctx, "ownproject", "kafka", "myteam.mytopic").Return(owned("dev-gcp"), nil).Once()
+
+		manager := taggedManager(m, "ownproject", topic.Ownership{Cluster: "dev-gcp"})
+		assert.NoError(t, manager.Synchronize(ctx))
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/freeze"
 	"github.com/nais/kafkarator/pkg/metrics"
 	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
 	log "github.com/sirupsen/logrus"
@@ -22,6 +24,8 @@ const (
 type Interface interface {
 	Get(ctx context.Context, project, service, topic string) (*aiven.KafkaTopic, error)
 	List(ctx context.Context, project, service string) ([]*aiven.KafkaListTopic, error)
+	// V2List returns the selected topics, with their configuration and tags, in a single request.
+	V2List(ctx context.Context, project, service string, topics []string) ([]*aiven.KafkaTopic, error)
 	Create(ctx context.Context, project, service string, req aiven.CreateKafkaTopicRequest) error
 	Update(ctx context.Context, project, service, topic string, req aiven.UpdateKafkaTopicRequest) error
 	Delete(ctx context.Context, project, service, topic string) error
@@ -32,8 +36,23 @@ type Manager struct {
 	Project     string
 	Service     string
 	Topic       kafka_nais_io_v1.Topic
-	Logger      *log.Entry
-	DryRun      bool
+	// ExtraConfig is topic configuration not modelled in the Topic spec.
+	ExtraConfig ExtraConfig
+	Ownership   Ownership
+	// AcknowledgedDataLoss is the synchronization hash of a spec allowed to change the topic in ways that delete data.
+	AcknowledgedDataLoss string
+	// Freeze defers creating and updating the topic while the pool is frozen. Changes are allowed if nil.
+	Freeze *freeze.Freeze
+	Logger *log.Entry
+	DryRun bool
+	// VerifyTimeout is how long Verify waits for the topic to become active in Aiven. It checks once if zero.
+	VerifyTimeout time.Duration
+	// VerifyInterval is the time between checks while verifying.
+	VerifyInterval time.Duration
+
+	existing *aiven.KafkaTopic
+	fetched  bool
+	checked  bool
 }
 
 func aivenError(err error) *aiven.Error {
@@ -45,23 +64,38 @@ func aivenError(err error) *aiven.Error {
 }
 
 func (r *Manager) Synchronize(ctx context.Context) error {
-	var topic *aiven.KafkaTopic
-	err := metrics.ObserveAivenLatency("Topic_Get", r.Project, func() error {
-		var err error
-		topic, err = r.AivenTopics.Get(ctx, r.Project, r.Service, r.Topic.FullName())
+	if err := r.CheckOwnership(ctx); err != nil {
 		return err
-	})
+	}
+	topic, err := r.get(ctx)
 	if err != nil {
-		aivenErr := aivenError(err)
-		if aivenErr != nil && aivenErr.Status == http.StatusNotFound {
-			r.Logger.Infof("Topic does not exist")
-			return r.create(ctx)
-		}
 		return err
 	}
+	if topic == nil {
+		r.Logger.Infof("Topic does not exist")
+		if err = r.Freeze.Check(fmt.Sprintf("create topic %s", r.Topic.FullName())); err != nil {
+			return err
+		}
+		return r.create(ctx)
+	}
 
 	// topic already exists
-	if topicConfigChanged(topic, r.Topic.Spec.Config) {
+	differences := topicConfigDifferences(topic, r.Topic.Spec.Config, r.ExtraConfig)
+	changed := len(differences) > 0
+	r.checkTags(topic, changed)
+	// Updating the tags of a topic scheduled for deletion cancels the deletion.
+	_, scheduled := DeletionDeadline(topic)
+	if scheduled {
+		r.Logger.Infof("Cancelling scheduled deletion of topic data")
+	}
+	if changed || scheduled || !managed(topic) || r.unclaimed(topic) {
+		change := fmt.Sprintf("update tags of topic %s", r.Topic.FullName())
+		if changed {
+			change = fmt.Sprintf("update topic %s (%s)", r.Topic.FullName(), strings.Join(differences, ", "))
+		}
+		if err = r.Freeze.Check(change); err != nil {
+			return err
+		}
 		r.Logger.Infof("Topic already exists")
 		return r.update(ctx)
 	}
@@ -69,6 +103,32 @@ func (r *Manager) Synchronize(ctx context.Context) error {
 	return nil
 }
 
+// get returns the topic from Aiven, or nil if it does not exist. The topic is only retrieved once.
+func (r *Manager) get(ctx context.Context) (*aiven.KafkaTopic, error) {
+	if r.fetched {
+		return r.existing, nil
+	}
+	err := metrics.ObserveAivenLatency("Topic_Get", r.Project, func() error {
+		var err error
+		r.existing, err = r.AivenTopics.Get(ctx, r.Project, r.Service, r.Topic.FullName())
+		return err
+	})
+	if err != nil {
+		aivenErr := aivenError(err)
+		if aivenErr == nil || aivenErr.Status != http.StatusNotFound {
+			return nil, err
+		}
+		r.existing = nil
+	}
+	r.fetched = true
+	return r.existing, nil
+}
+
+// Existing returns the topic in Aiven, or nil if it does not exist.
+func (r *Manager) Existing(ctx context.Context) (*aiven.KafkaTopic, error) {
+	return r.get(ctx)
+}
+
 func (r *Manager) List(ctx context.Context) ([]*aiven.KafkaListTopic, error) {
 	var list []*aiven.KafkaListTopic
 	err := metrics.ObserveAivenLatency("Topic_List", r.Project, func() error {
@@ -114,11 +174,9 @@ func (r *Manager) create(ctx context.Context) error {
 			MinCompactionLagMs:     intpToInt64p(cfg.MinCompactionLagMs),
 			MaxCompactionLagMs:     intpToInt64p(cfg.MaxCompactionLagMs),
 		},
-		Tags: []aiven.KafkaTopicTag{
-			{Key: "created-by", Value: "Kafkarator"},
-			{Key: "touched-at", Value: time.Now().Format(time.RFC3339)},
-		},
+		Tags: r.tags(),
 	}
+	r.ExtraConfig.apply(&req.Config)
 
 	return metrics.ObserveAivenLatency("Topic_Create", r.Project, func() error {
 		if r.DryRun {
@@ -133,7 +191,7 @@ func (r *Manager) update(ctx context.Context) error {
 	r.Logger.Infof("Updating topic")
 
 	cfg := r.Topic.Spec.Config
-	// below code should never run - should not be nil due to topicConfigChanged()
+	// below code should never run - should not be nil due to topicConfigDifferences()
 	if cfg == nil {
 		cfg = &kafka_nais_io_v1.Config{}
 	}
@@ -163,11 +221,9 @@ func (r *Manager) update(ctx context.Context) error {
 			MinCompactionLagMs:     intpToInt64p(cfg.MinCompactionLagMs),
 			MaxCompactionLagMs:     intpToInt64p(cfg.MaxCompactionLagMs),
 		},
-		Tags: []aiven.KafkaTopicTag{
-			{Key: "created-by", Value: "Kafkarator"},
-			{Key: "touched-at", Value: time.Now().Format(time.RFC3339)},
-		},
+		Tags: r.tags(),
 	}
+	r.ExtraConfig.apply(&req.Config)
 
 	return metrics.ObserveAivenLatency("Topic_Update", r.Project, func() error {
 		if r.DryRun {
@@ -179,76 +235,77 @@ func (r *Manager) update(ctx context.Context) error {
 }
 
 func enableRemoteStorage(cfg *kafka_nais_io_v1.Config) *bool {
-	if cfg.LocalRetentionBytes != nil && *cfg.LocalRetentionBytes > 0 {
-		return new(true)
-	}
-	if cfg.LocalRetentionHours != nil && *cfg.LocalRetentionHours > 0 {
+	if RemoteStorage(cfg) {
 		return new(true)
 	}
 	return nil
 }
 
-func topicConfigChanged(topic *aiven.KafkaTopic, config *kafka_nais_io_v1.Config) bool {
-	if config == nil {
-		return false
-	}
-
-	if config.Replication != nil && topic.Replication != *config.Replication {
-		return true
-	}
-
-	if config.Partitions != nil && len(topic.Partitions) != *config.Partitions {
-		return true
-	}
-
-	if config.RetentionHours != nil && topic.Config.RetentionMs.Value != *retentionMs(config.RetentionHours, retentionHourDefault) {
-		return true
-	}
-
-	if intPToValueChanged(config.RetentionBytes, topic.Config.RetentionBytes) {
-		return true
-	}
-
-	if config.DeleteRetentionHours != nil && topic.Config.DeleteRetentionMs.Value != *retentionMs(config.DeleteRetentionHours, deleteRetentionHourDefault) {
-		return true
-	}
-
-	if config.LocalRetentionHours != nil && topic.Config.LocalRetentionMs.Value != *retentionMs(config.LocalRetentionHours, localRetentionHourDefault) {
+// RemoteStorage returns true if the topic keeps data in tiered storage, which is the case when local retention is set.
+func RemoteStorage(cfg *kafka_nais_io_v1.Config) bool {
+	if cfg.LocalRetentionBytes != nil && *cfg.LocalRetentionBytes > 0 {
 		return true
 	}
+	return cfg.LocalRetentionHours != nil && *cfg.LocalRetentionHours > 0
+}
 
-	if intPToValueChanged(config.LocalRetentionBytes, topic.Config.LocalRetentionBytes) {
-		return true
+// topicConfigDifferences describes every setting in the spec that differs from the topic in Aiven, on the form
+// "name: current -> wanted". Settings not set in the spec are left out.
+func topicConfigDifferences(topic *aiven.KafkaTopic, config *kafka_nais_io_v1.Config, extra ExtraConfig) []string {
+	differences := extra.differences(topic.Config)
+	if config == nil {
+		return differences
 	}
 
-	if intPToValueChanged(config.MinimumInSyncReplicas, topic.Config.MinInsyncReplicas) {
-		return true
+	diff := func(name string, current, wanted any) {
+		differences = append(differences, fmt.Sprintf("%s: %v -> %v", name, current, wanted))
 	}
-
-	if config.SegmentHours != nil && topic.Config.SegmentMs.Value != *segmentMs(config) {
-		return true
+	diffInt := func(name string, wanted *int64, current *aiven.KafkaTopicConfigResponseInt) {
+		switch {
+		case wanted == nil:
+		case current == nil:
+			diff(name, "unset", *wanted)
+		case current.Value != *wanted:
+			diff(name, current.Value, *wanted)
+		}
 	}
 
-	if intPToValueChanged(config.MaxMessageBytes, topic.Config.MaxMessageBytes) {
-		return true
+	if config.Replication != nil && topic.Replication != *config.Replication {
+		diff("replication", topic.Replication, *config.Replication)
 	}
-
-	if intPToValueChanged(config.MinCompactionLagMs, topic.Config.MinCompactionLagMs) {
-		return true
+	if config.Partitions != nil && len(topic.Partitions) != *config.Partitions {
+		diff("partitions", len(topic.Partitions), *config.Partitions)
+	}
+	if wanted := cleanupPolicy(config); len(wanted) > 0 {
+		switch {
+		case topic.Config.CleanupPolicy == nil:
+			diff("cleanup.policy", "unset", wanted)
+		case topic.Config.CleanupPolicy.Value != wanted:
+			diff("cleanup.policy", topic.Config.CleanupPolicy.Value, wanted)
+		}
 	}
-
-	if intPToValueChanged(config.MaxCompactionLagMs, topic.Config.MaxCompactionLagMs) {
-		return true
+	diffInt("retention.ms", retentionMs(config.RetentionHours, retentionHourDefault), topic.Config.RetentionMs)
+	diffInt("retention.bytes", intpToInt64p(config.RetentionBytes), topic.Config.RetentionBytes)
+	diffInt("delete.retention.ms", retentionMs(config.DeleteRetentionHours, deleteRetentionHourDefault), topic.Config.DeleteRetentionMs)
+	diffInt("local.retention.ms", retentionMs(config.LocalRetentionHours, localRetentionHourDefault), topic.Config.LocalRetentionMs)
+	diffInt("local.retention.bytes", intpToInt64p(config.LocalRetentionBytes), topic.Config.LocalRetentionBytes)
+	diffInt("min.insync.replicas", intpToInt64p(config.MinimumInSyncReplicas), topic.Config.MinInsyncReplicas)
+	if config.SegmentHours != nil {
+		diffInt("segment.ms", segmentMs(config), topic.Config.SegmentMs)
 	}
+	diffInt("max.message.bytes", intpToInt64p(config.MaxMessageBytes), topic.Config.MaxMessageBytes)
+	diffInt("min.compaction.lag.ms", intpToInt64p(config.MinCompactionLagMs), topic.Config.MinCompactionLagMs)
+	diffInt("max.compaction.lag.ms", intpToInt64p(config.MaxCompactionLagMs), topic.Config.MaxCompactionLagMs)
 
 	if config.MinCleanableDirtyRatioPercent != nil {
 		ratio, err := percentToRatio(config.MinCleanableDirtyRatioPercent)
-		if err != nil || topic.Config.MinCleanableDirtyRatio.Value != *ratio {
-			return true
+		if err != nil {
+			diff("min.cleanable.dirty.ratio", topic.Config.MinCleanableDirtyRatio.Value, config.MinCleanableDirtyRatioPercent.String())
+		} else if topic.Config.MinCleanableDirtyRatio.Value != *ratio {
+			diff("min.cleanable.dirty.ratio", topic.Config.MinCleanableDirtyRatio.Value, *ratio)
 		}
-
 	}
-	return false
+	return differences
 }
 
 func percentToRatio(percent *intstr.IntOrString) (*float64, error) {
@@ -266,10 +323,6 @@ func percentToRatio(percent *intstr.IntOrString) (*float64, error) {
 	return &ratio, nil
 }
 
-func intPToValueChanged(cfg *int, tcfg *aiven.KafkaTopicConfigResponseInt) bool {
-	return cfg != nil && tcfg.Value != int64(*cfg)
-}
-
 func retentionMs(hours *int, dflt int) *int64 {
 	if hours == nil {
 		return nil
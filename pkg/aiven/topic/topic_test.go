@@ -20,6 +20,8 @@ import (
 type topicTest struct {
 	name     string
 	topic    kafka_nais_io_v1.Topic         // input
+	extra    topic.ExtraConfig              // input
+	owner    topic.Ownership                // input
 	project  string                         // expected kafka project
 	service  string                         // expected kafka service
 	existing *aiven.KafkaTopic              // expected return from getting existing topic
@@ -29,6 +31,132 @@ type topicTest struct {
 }
 
 var tests = []topicTest{
+	{
+		name: "create a topic with ownership tags",
+		topic: kafka_nais_io_v1.Topic{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "mytopic",
+				Namespace:  "myteam",
+				UID:        "c0ffee",
+				Generation: 4,
+			},
+			Spec: kafka_nais_io_v1.TopicSpec{
+				Pool: "mypool",
+			},
+		},
+		owner:   topic.Ownership{Cluster: "dev-gcp", Hash: "abc123"},
+		project: "someproject",
+		service: "mypool-kafka",
+		create: &aiven.CreateKafkaTopicRequest{
+			TopicName: "myteam.mytopic",
+			Tags: []aiven.KafkaTopicTag{
+				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "cluster", Value: "dev-gcp"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
+				{Key: "uid", Value: "c0ffee"},
+				{Key: "generation", Value: "4"},
+				{Key: "synchronization-hash", Value: "abc123"},
+			},
+		},
+	},
+
+	{
+		name: "create a topic with extra configuration",
+		topic: kafka_nais_io_v1.Topic{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "mytopic",
+				Namespace: "myteam",
+			},
+			Spec: kafka_nais_io_v1.TopicSpec{
+				Pool: "mypool",
+				Config: &kafka_nais_io_v1.Config{
+					Partitions: new(2),
+				},
+			},
+		},
+		extra: topic.ExtraConfig{
+			"compression.type":              "zstd",
+			"message.downconversion.enable": "false",
+			"segment.bytes":                 "1048576",
+		},
+		project: "someproject",
+		service: "mypool-kafka",
+		create: &aiven.CreateKafkaTopicRequest{
+			Partitions: new(2),
+			TopicName:  "myteam.mytopic",
+			Config: aiven.KafkaTopicConfig{
+				CompressionType:             "zstd",
+				MessageDownconversionEnable: new(false),
+				SegmentBytes:                new(int64(1048576)),
+			},
+			Tags: []aiven.KafkaTopicTag{
+				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
+			},
+		},
+	},
+
+	{
+		name: "update a topic when extra configuration changes",
+		topic: kafka_nais_io_v1.Topic{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "mytopic",
+				Namespace: "myteam",
+			},
+			Spec: kafka_nais_io_v1.TopicSpec{
+				Pool: "mypool",
+			},
+		},
+		extra: topic.ExtraConfig{
+			"compression.type": "zstd",
+		},
+		project: "someproject",
+		service: "mypool-kafka",
+		existing: &aiven.KafkaTopic{
+			Config: aiven.KafkaTopicConfigResponse{
+				CompressionType: &aiven.KafkaTopicConfigResponseString{Value: "producer"},
+			},
+		},
+		update: &aiven.UpdateKafkaTopicRequest{
+			Config: aiven.KafkaTopicConfig{
+				CompressionType: "zstd",
+			},
+			Tags: []aiven.KafkaTopicTag{
+				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
+			},
+		},
+	},
+
+	{
+		name: "skip updating a topic with unchanged extra configuration",
+		topic: kafka_nais_io_v1.Topic{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "mytopic",
+				Namespace: "myteam",
+			},
+			Spec: kafka_nais_io_v1.TopicSpec{
+				Pool: "mypool",
+			},
+		},
+		extra: topic.ExtraConfig{
+			"compression.type": "zstd",
+			"segment.bytes":    "1048576",
+		},
+		project: "someproject",
+		service: "mypool-kafka",
+		existing: &aiven.KafkaTopic{
+			Config: aiven.KafkaTopicConfigResponse{
+				CompressionType: &aiven.KafkaTopicConfigResponseString{Value: "zstd"},
+				SegmentBytes:    &aiven.KafkaTopicConfigResponseInt{Value: 1048576},
+			},
+			Tags: []aiven.KafkaTopicTag{{Key: topic.TagCreatedBy, Value: "Kafkarator"}},
+		},
+	},
+
 	{
 		name: "create a new topic",
 		topic: kafka_nais_io_v1.Topic{
@@ -52,6 +180,8 @@ var tests = []topicTest{
 			TopicName:   "myteam.mytopic",
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 	},
@@ -79,6 +209,8 @@ var tests = []topicTest{
 			Replication: new(3),
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 	},
@@ -131,6 +263,8 @@ var tests = []topicTest{
 			},
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 	},
@@ -173,6 +307,8 @@ var tests = []topicTest{
 			},
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 	},
@@ -219,6 +355,8 @@ var tests = []topicTest{
 			},
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 	},
@@ -256,6 +394,8 @@ var tests = []topicTest{
 			},
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 	},
@@ -290,6 +430,8 @@ var tests = []topicTest{
 			},
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 	},
@@ -311,6 +453,8 @@ var tests = []topicTest{
 			TopicName: "myteam.mytopic",
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 		error: map[string]bool{
@@ -335,6 +479,8 @@ var tests = []topicTest{
 			TopicName: "myteam.mytopic",
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 		error: map[string]bool{
@@ -371,6 +517,8 @@ var tests = []topicTest{
 			},
 			Tags: []aiven.KafkaTopicTag{
 				{Key: "created-by", Value: "Kafkarator"},
+				{Key: "namespace", Value: "myteam"},
+				{Key: "name", Value: "mytopic"},
 			},
 		},
 		error: map[string]bool{
@@ -433,6 +581,8 @@ func subTest(ctx context.Context, t *testing.T, test topicTest) {
 	manager := topic.Manager{
 		AivenTopics: m,
 		Topic:       test.topic,
+		ExtraConfig: test.extra,
+		Ownership:   test.owner,
 		Project:     test.project,
 		Service:     test.service,
 		Logger:      log.NewEntry(log.StandardLogger()),
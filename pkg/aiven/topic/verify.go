@@ -0,0 +1,84 @@
+package topic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StateActive is the state of topics in Aiven that are ready for use. Topics are CONFIGURING while being created
+// or updated.
+const StateActive = "ACTIVE"
+
+const defaultVerifyInterval = 2 * time.Second
+
+// NotReadyError is returned by Verify when the topic in Aiven did not become active with the wanted configuration
+// before the timeout.
+type NotReadyError struct {
+	Topic string
+	// State is the last observed state of the topic in Aiven, or empty if it was not found.
+	State       string
+	Differences []string
+}
+
+func (e *NotReadyError) Error() string {
+	switch {
+	case len(e.State) == 0:
+		return fmt.Sprintf("topic '%s' not found in Aiven after synchronization", e.Topic)
+	case e.State != StateActive:
+		return fmt.Sprintf("topic '%s' is %s in Aiven, waiting for it to become %s", e.Topic, e.State, StateActive)
+	default:
+		return fmt.Sprintf("topic '%s' in Aiven differs from the spec after synchronization: %s", e.Topic, strings.Join(e.Differences, ", "))
+	}
+}
+
+// Verify reads the topic from Aiven again after synchronizing, until it is active and matches the spec.
+// A NotReadyError with the last observed state is returned if that does not happen within VerifyTimeout.
+func (r *Manager) Verify(ctx context.Context) error {
+	if r.DryRun {
+		r.Logger.Infof("DRY RUN: Would verify Topic: %v", r.Topic.FullName())
+		return nil
+	}
+
+	interval := r.VerifyInterval
+	if interval <= 0 {
+		interval = defaultVerifyInterval
+	}
+	deadline := time.Now().Add(r.VerifyTimeout)
+	for {
+		err := r.verify(ctx)
+		if err == nil {
+			return nil
+		}
+		notReady, ok := err.(*NotReadyError)
+		if !ok || time.Now().Add(interval).After(deadline) {
+			return err
+		}
+		r.Logger.Infof("Waiting for topic in Aiven: %s", notReady)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (r *Manager) verify(ctx context.Context) error {
+	r.fetched = false
+	topic, err := r.get(ctx)
+	if err != nil {
+		return err
+	}
+	if topic == nil {
+		return &NotReadyError{Topic: r.Topic.FullName()}
+	}
+	if topic.State != StateActive {
+		return &NotReadyError{Topic: r.Topic.FullName(), State: topic.State}
+	}
+	if differences := topicConfigDifferences(topic, r.Topic.Spec.Config, r.ExtraConfig); len(differences) > 0 {
+		return &NotReadyError{Topic: r.Topic.FullName(), State: topic.State, Differences: differences}
+	}
+	r.Logger.Infof("Topic is %s in Aiven", topic.State)
+	return nil
+}
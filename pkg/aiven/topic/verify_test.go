@@ -0,0 +1,56 @@
+package topic_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+)
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	configuring := &aiven.KafkaTopic{State: "CONFIGURING", Replication: 3}
+	active := &aiven.KafkaTopic{State: topic.StateActive, Replication: 3}
+	notFound := aiven.Error{Status: http.StatusNotFound}
+
+	t.Run("waits for the topic to become active", func(t *testing.T) {
+		m := topic.NewMockInterface(t)
+		m.On("Get", ctx, "verifyproject", "kafka", "myteam.mytopic").Return(nil, notFound).Once()
+		m.On("Get", ctx, "verifyproject", "kafka", "myteam.mytopic").Return(configuring, nil).Once()
+		m.On("Get", ctx, "verifyproject", "kafka", "myteam.mytopic").Return(active, nil).Once()
+		manager := taggedManager(m, "verifyproject", topic.Ownership{})
+		manager.VerifyTimeout = time.Second
+		manager.VerifyInterval = time.Millisecond
+
+		assert.NoError(t, manager.Verify(ctx))
+	})
+
+	t.Run("reports the state when timing out", func(t *testing.T) {
+		m := topic.NewMockInterface(t)
+		m.On("Get", ctx, "verifyproject", "kafka", "myteam.mytopic").Return(configuring, nil)
+		manager := taggedManager(m, "verifyproject", topic.Ownership{})
+		manager.VerifyTimeout = 10 * time.Millisecond
+		manager.VerifyInterval = time.Millisecond
+
+		err := manager.Verify(ctx)
+		assert.Equal(t, &topic.NotReadyError{Topic: "myteam.mytopic", State: "CONFIGURING"}, err)
+		assert.EqualError(t, err, "topic 'myteam.mytopic' is CONFIGURING in Aiven, waiting for it to become ACTIVE")
+	})
+
+	t.Run("reports differences from the spec", func(t *testing.T) {
+		m := topic.NewMockInterface(t)
+		m.On("Get", ctx, "verifyproject", "kafka", "myteam.mytopic").Return(&aiven.KafkaTopic{State: topic.StateActive, Replication: 2}, nil).Once()
+		manager := taggedManager(m, "verifyproject", topic.Ownership{})
+
+		err := manager.Verify(ctx)
+		var notReady *topic.NotReadyError
+		assert.ErrorAs(t, err, &notReady)
+		assert.Equal(t, topic.StateActive, notReady.State)
+		assert.NotEmpty(t, notReady.Differences)
+	})
+}
@@ -0,0 +1,292 @@
+package kafkarator_nais_io_v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nais/kafkarator/pkg/freeze"
+	"github.com/nais/kafkarator/pkg/topicconfig"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func init() {
+	SchemeBuilder.Register(
+		&KafkaPool{},
+		&KafkaPoolList{},
+	)
+}
+
+// +kubebuilder:object:root=true
+type KafkaPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KafkaPool `json:"items"`
+}
+
+// KafkaPool declares a Kafka pool that Topic and Stream resources in this cluster can use.
+// The name of the resource is the pool name referenced in `spec.pool`.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Project",type="string",JSONPath=".spec.project"
+// +kubebuilder:printcolumn:name="Service",type="string",JSONPath=".status.service"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.serviceState"
+// +kubebuilder:printcolumn:name="Maintenance",type="boolean",JSONPath=".spec.maintenance.enabled"
+type KafkaPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              KafkaPoolSpec   `json:"spec"`
+	Status            KafkaPoolStatus `json:"status,omitempty"`
+}
+
+type KafkaPoolSpec struct {
+	// Aiven project that hosts the Kafka service.
+	// +kubebuilder:validation:MinLength=1
+	Project string `json:"project"`
+	// Kafka service in the project. If not set, the Kafka service is found by naming convention.
+	Service string `json:"service,omitempty"`
+	// Namespaces allowed to use this pool. If empty, all namespaces may use the pool, unless restricted by access rules.
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+	// Namespace access rules for this pool.
+	Access *KafkaPoolAccess `json:"access,omitempty"`
+	// Topic configuration used for settings that are not set on the Topic itself.
+	// Settings set neither on the Topic nor here use the Kafkarator defaults.
+	TopicDefaults *kafka_nais_io_v1.Config `json:"topicDefaults,omitempty"`
+	// Upper limits for topic configuration in this pool.
+	Limits *KafkaPoolLimits `json:"limits,omitempty"`
+	// Upper limits for the total usage of each namespace in this pool.
+	Quotas *KafkaPoolQuotas `json:"quotas,omitempty"`
+	// While in maintenance, resources using this pool are not synchronized, and are retried later.
+	Maintenance *KafkaPoolMaintenance `json:"maintenance,omitempty"`
+	// While frozen, changes to topics and ACLs in this pool are deferred until the freeze ends.
+	// Resources are still read and checked against Aiven.
+	Freeze *KafkaPoolFreeze `json:"freeze,omitempty"`
+}
+
+// KafkaPoolAccess restricts which namespaces may use a pool.
+// Deny rules take precedence over allow rules. If there are allow rules, or allowed namespaces,
+// only namespaces matching at least one of them may use the pool.
+type KafkaPoolAccess struct {
+	Allow []NamespaceRule `json:"allow,omitempty"`
+	Deny  []NamespaceRule `json:"deny,omitempty"`
+}
+
+// NamespaceRule matches namespaces by name or by labels. A namespace matching either matches the rule.
+type NamespaceRule struct {
+	Namespaces        []string              `json:"namespaces,omitempty"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+type KafkaPoolLimits struct {
+	// +kubebuilder:validation:Minimum=1
+	MaxPartitions *int `json:"maxPartitions,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MaxReplication *int `json:"maxReplication,omitempty"`
+	// Topics with infinite retention are not allowed when set.
+	// +kubebuilder:validation:Minimum=1
+	MaxRetentionHours *int `json:"maxRetentionHours,omitempty"`
+}
+
+type KafkaPoolQuotas struct {
+	// Quota for namespaces without a specific quota. Namespaces are not limited if not set.
+	Default *NamespaceQuota `json:"default,omitempty"`
+	// Quotas for specific namespaces.
+	Namespaces map[string]NamespaceQuota `json:"namespaces,omitempty"`
+}
+
+type NamespaceQuota struct {
+	// +kubebuilder:validation:Minimum=0
+	MaxTopics *int `json:"maxTopics,omitempty"`
+	// Total partitions of all topics.
+	// +kubebuilder:validation:Minimum=0
+	MaxPartitions *int `json:"maxPartitions,omitempty"`
+	// Total of retentionBytes multiplied by partitions for all topics.
+	// Topics without a retentionBytes limit are not allowed when set.
+	// +kubebuilder:validation:Minimum=0
+	MaxRetainedBytes *int64 `json:"maxRetainedBytes,omitempty"`
+}
+
+type KafkaPoolMaintenance struct {
+	Enabled bool `json:"enabled"`
+	// Shown in the status of resources waiting for the maintenance to end.
+	Reason string `json:"reason,omitempty"`
+}
+
+type KafkaPoolFreeze struct {
+	// Freezes the pool until disabled, regardless of the windows.
+	Enabled bool `json:"enabled,omitempty"`
+	// Shown in the status of resources waiting for the freeze to end.
+	Reason string `json:"reason,omitempty"`
+	// Recurring freeze windows.
+	Windows []FreezeWindow `json:"windows,omitempty"`
+}
+
+type FreezeWindow struct {
+	// Start of each window, as a cron schedule with minute, hour, day of month, month and day of week,
+	// i.e. "0 16 * * 5" for Fridays at 16:00.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// Length of each window, i.e. "64h".
+	Duration metav1.Duration `json:"duration"`
+	// Time zone of the schedule, i.e. "Europe/Oslo". Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Shown in the status of resources waiting for the window to end, instead of the reason of the freeze.
+	Reason string `json:"reason,omitempty"`
+}
+
+// KafkaPoolStatus is written by Kafkarator from the state of the Aiven service.
+type KafkaPoolStatus struct {
+	Service      string `json:"service,omitempty"`
+	ServiceState string `json:"serviceState,omitempty"`
+	KafkaVersion string `json:"kafkaVersion,omitempty"`
+	Plan         string `json:"plan,omitempty"`
+	NodeCount    int    `json:"nodeCount,omitempty"`
+	// Time of the latest check against Aiven, in RFC3339 format.
+	LastChecked string `json:"lastChecked,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+// HasNamespaceSelectors returns true if the access rules need the labels of a namespace.
+func (in *KafkaPool) HasNamespaceSelectors() bool {
+	if in.Spec.Access == nil {
+		return false
+	}
+	for _, rule := range slices.Concat(in.Spec.Access.Allow, in.Spec.Access.Deny) {
+		if rule.NamespaceSelector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckNamespace returns an error describing why resources in the namespace may not use the pool, if they may not.
+func (in *KafkaPool) CheckNamespace(namespace string, namespaceLabels map[string]string) error {
+	var allow, deny []NamespaceRule
+	if in.Spec.Access != nil {
+		allow, deny = in.Spec.Access.Allow, in.Spec.Access.Deny
+	}
+	if len(in.Spec.AllowedNamespaces) > 0 {
+		allow = slices.Concat(allow, []NamespaceRule{{Namespaces: in.Spec.AllowedNamespaces}})
+	}
+
+	for _, rule := range deny {
+		matches, err := rule.Matches(namespace, namespaceLabels)
+		if err != nil {
+			return err
+		}
+		if matches {
+			return errors.New("denied by access rule")
+		}
+	}
+
+	if len(allow) == 0 {
+		return nil
+	}
+	for _, rule := range allow {
+		matches, err := rule.Matches(namespace, namespaceLabels)
+		if err != nil {
+			return err
+		}
+		if matches {
+			return nil
+		}
+	}
+	return errors.New("not allowed by any access rule")
+}
+
+func (rule NamespaceRule) Matches(namespace string, namespaceLabels map[string]string) (bool, error) {
+	if slices.Contains(rule.Namespaces, namespace) {
+		return true, nil
+	}
+	if rule.NamespaceSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(rule.NamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+	return selector.Matches(labels.Set(namespaceLabels)), nil
+}
+
+// QuotaFor returns the quota of a namespace, or nil if the namespace is not limited.
+func (in *KafkaPool) QuotaFor(namespace string) *NamespaceQuota {
+	if in.Spec.Quotas == nil {
+		return nil
+	}
+	if quota, ok := in.Spec.Quotas.Namespaces[namespace]; ok {
+		return &quota
+	}
+	return in.Spec.Quotas.Default
+}
+
+// InMaintenance returns true if synchronization against the pool is paused.
+func (in *KafkaPool) InMaintenance() bool {
+	return in.Spec.Maintenance != nil && in.Spec.Maintenance.Enabled
+}
+
+// Frozen returns the change freeze in effect for the pool at the given time, or nil if changes are allowed.
+// Of overlapping windows, the freeze lasts until the last one ends.
+func (in *KafkaPool) Frozen(now time.Time) (*freeze.Freeze, error) {
+	spec := in.Spec.Freeze
+	if spec == nil {
+		return nil, nil
+	}
+	if spec.Enabled {
+		return &freeze.Freeze{Pool: in.Name, Reason: spec.Reason}, nil
+	}
+
+	var frozen *freeze.Freeze
+	for _, window := range spec.Windows {
+		location, err := time.LoadLocation(window.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("freeze window of pool '%s': invalid time zone: %w", in.Name, err)
+		}
+		schedule, err := freeze.ParseSchedule(window.Schedule, location)
+		if err != nil {
+			return nil, fmt.Errorf("freeze window of pool '%s': %w", in.Name, err)
+		}
+		until, ok := schedule.WindowEnd(now, window.Duration.Duration)
+		if !ok || (frozen != nil && !until.After(frozen.Until)) {
+			continue
+		}
+		frozen = &freeze.Freeze{Pool: in.Name, Reason: window.Reason, Until: until}
+		if len(frozen.Reason) == 0 {
+			frozen.Reason = spec.Reason
+		}
+	}
+	return frozen, nil
+}
+
+// ApplyTopicDefaults sets every unset field in cfg to the pool's topic default, if any.
+func (in *KafkaPool) ApplyTopicDefaults(cfg *kafka_nais_io_v1.Config) {
+	topicconfig.Fill(cfg, in.Spec.TopicDefaults)
+}
+
+// CheckLimits returns an error if the topic configuration exceeds the pool limits.
+func (in *KafkaPool) CheckLimits(cfg *kafka_nais_io_v1.Config) error {
+	limits := in.Spec.Limits
+	if limits == nil {
+		return nil
+	}
+	if exceeds(cfg.Partitions, limits.MaxPartitions) {
+		return fmt.Errorf("partitions (%d) exceeds the limit of pool '%s' (%d)", *cfg.Partitions, in.Name, *limits.MaxPartitions)
+	}
+	if exceeds(cfg.Replication, limits.MaxReplication) {
+		return fmt.Errorf("replication (%d) exceeds the limit of pool '%s' (%d)", *cfg.Replication, in.Name, *limits.MaxReplication)
+	}
+	if limits.MaxRetentionHours != nil && cfg.RetentionHours != nil && *cfg.RetentionHours < 0 {
+		return fmt.Errorf("infinite retention is not allowed in pool '%s'", in.Name)
+	}
+	if exceeds(cfg.RetentionHours, limits.MaxRetentionHours) {
+		return fmt.Errorf("retentionHours (%d) exceeds the limit of pool '%s' (%d)", *cfg.RetentionHours, in.Name, *limits.MaxRetentionHours)
+	}
+	return nil
+}
+
+func exceeds(value, limit *int) bool {
+	return value != nil && limit != nil && *value > *limit
+}
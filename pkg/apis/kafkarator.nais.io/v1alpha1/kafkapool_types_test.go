@@ -0,0 +1,118 @@
+package kafkarator_nais_io_v1alpha1_test
+
+import (
+	"testing"
+	"time"
+
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	"github.com/nais/kafkarator/pkg/freeze"
+)
+
+func TestApplyTopicDefaults(t *testing.T) {
+	kafkaPool := &kafkarator_nais_io_v1alpha1.KafkaPool{
+		Spec: kafkarator_nais_io_v1alpha1.KafkaPoolSpec{
+			TopicDefaults: &kafka_nais_io_v1.Config{
+				Partitions:     new(6),
+				RetentionHours: new(72),
+			},
+		},
+	}
+	cfg := &kafka_nais_io_v1.Config{
+		RetentionHours: new(24),
+	}
+
+	kafkaPool.ApplyTopicDefaults(cfg)
+	assert.Equal(t, 6, *cfg.Partitions)
+	assert.Equal(t, 24, *cfg.RetentionHours)
+	assert.Nil(t, cfg.Replication)
+
+	*cfg.Partitions = 7
+	assert.Equal(t, 6, *kafkaPool.Spec.TopicDefaults.Partitions)
+}
+
+func TestCheckLimits(t *testing.T) {
+	kafkaPool := &kafkarator_nais_io_v1alpha1.KafkaPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "nav-dev"},
+		Spec: kafkarator_nais_io_v1alpha1.KafkaPoolSpec{
+			Limits: &kafkarator_nais_io_v1alpha1.KafkaPoolLimits{
+				MaxPartitions:     new(12),
+				MaxRetentionHours: new(720),
+			},
+		},
+	}
+
+	assert.NoError(t, kafkaPool.CheckLimits(&kafka_nais_io_v1.Config{Partitions: new(12), RetentionHours: new(720)}))
+	assert.EqualError(t, kafkaPool.CheckLimits(&kafka_nais_io_v1.Config{Partitions: new(24)}), "partitions (24) exceeds the limit of pool 'nav-dev' (12)")
+	assert.EqualError(t, kafkaPool.CheckLimits(&kafka_nais_io_v1.Config{RetentionHours: new(-1)}), "infinite retention is not allowed in pool 'nav-dev'")
+	assert.EqualError(t, kafkaPool.CheckLimits(&kafka_nais_io_v1.Config{RetentionHours: new(1000)}), "retentionHours (1000) exceeds the limit of pool 'nav-dev' (720)")
+}
+
+func TestCheckNamespace(t *testing.T) {
+	kafkaPool := &kafkarator_nais_io_v1alpha1.KafkaPool{
+		Spec: kafkarator_nais_io_v1alpha1.KafkaPoolSpec{
+			AllowedNamespaces: []string{"nais-system"},
+			Access: &kafkarator_nais_io_v1alpha1.KafkaPoolAccess{
+				Allow: []kafkarator_nais_io_v1alpha1.NamespaceRule{
+					{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "true"}}},
+				},
+				Deny: []kafkarator_nais_io_v1alpha1.NamespaceRule{
+					{Namespaces: []string{"sandbox"}},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, kafkaPool.CheckNamespace("nais-system", nil))
+	assert.NoError(t, kafkaPool.CheckNamespace("myteam", map[string]string{"team": "true"}))
+	assert.EqualError(t, kafkaPool.CheckNamespace("sandbox", map[string]string{"team": "true"}), "denied by access rule")
+	assert.EqualError(t, kafkaPool.CheckNamespace("default", nil), "not allowed by any access rule")
+	assert.Len(t, kafkaPool.Spec.Access.Allow, 1)
+
+	assert.NoError(t, (&kafkarator_nais_io_v1alpha1.KafkaPool{}).CheckNamespace("default", nil))
+}
+
+func TestFrozen(t *testing.T) {
+	kafkaPool := &kafkarator_nais_io_v1alpha1.KafkaPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "nav-prod"},
+		Spec: kafkarator_nais_io_v1alpha1.KafkaPoolSpec{
+			Freeze: &kafkarator_nais_io_v1alpha1.KafkaPoolFreeze{
+				Reason: "Change freeze",
+				Windows: []kafkarator_nais_io_v1alpha1.FreezeWindow{
+					{Schedule: "0 16 * * 5", Duration: metav1.Duration{Duration: 64 * time.Hour}, TimeZone: "Europe/Oslo", Reason: "Weekend"},
+					{Schedule: "0 6 * * 1", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+				},
+			},
+		},
+	}
+
+	// Thursday 2026-03-05 12:00 UTC
+	frozen, err := kafkaPool.Frozen(time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Nil(t, frozen)
+
+	// Saturday, in the weekend window ending Monday 08:00 in Oslo
+	frozen, err = kafkaPool.Frozen(time.Date(2026, time.March, 7, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, "Weekend", frozen.Reason)
+	assert.True(t, time.Date(2026, time.March, 9, 7, 0, 0, 0, time.UTC).Equal(frozen.Until))
+
+	// Monday, in both windows; the freeze lasts until the last one ends
+	frozen, err = kafkaPool.Frozen(time.Date(2026, time.March, 9, 6, 30, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, &freeze.Freeze{Pool: "nav-prod", Reason: "Change freeze", Until: time.Date(2026, time.March, 9, 10, 0, 0, 0, time.UTC)}, frozen)
+
+	kafkaPool.Spec.Freeze.Enabled = true
+	frozen, err = kafkaPool.Frozen(time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, &freeze.Freeze{Pool: "nav-prod", Reason: "Change freeze"}, frozen)
+
+	kafkaPool.Spec.Freeze = &kafkarator_nais_io_v1alpha1.KafkaPoolFreeze{
+		Windows: []kafkarator_nais_io_v1alpha1.FreezeWindow{{Schedule: "0 25 * * *"}},
+	}
+	_, err = kafkaPool.Frozen(time.Now())
+	assert.EqualError(t, err, "freeze window of pool 'nav-prod': invalid schedule '0 25 * * *': invalid hour '25'; must be 0-23")
+}
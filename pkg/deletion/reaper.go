@@ -0,0 +1,213 @@
+// Package deletion deletes the data of deleted topics once their grace period has passed.
+//
+// Deleted Topic resources with data scheduled for deletion leave the topic in Aiven, tagged with the time the data
+// may be deleted. The reaper keeps track of these topics, and deletes them when they are due, unless the Topic
+// resource has been recreated and the tag removed in the meantime.
+package deletion
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	"github.com/nais/kafkarator/pkg/kafkapool"
+	"github.com/nais/kafkarator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// scanBatchSize is the number of topics read from Aiven per request when scanning for topics scheduled for deletion.
+const scanBatchSize = 100
+
+type pending struct {
+	Pool  string
+	Topic string
+}
+
+type Reaper struct {
+	Pools  *kafkapool.Registry
+	Topics topic.Interface
+	// ClusterName is the owner of the topics the reaper may delete.
+	ClusterName string
+	Interval    time.Duration
+	Logger      log.FieldLogger
+	DryRun      bool
+
+	mu      sync.Mutex
+	pending map[pending]struct{}
+	scanned bool
+}
+
+// Track adds a topic scheduled for deletion. A nil reaper tracks nothing.
+func (r *Reaper) Track(pool, topicName string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		r.pending = make(map[pending]struct{})
+	}
+	r.pending[pending{Pool: pool, Topic: topicName}] = struct{}{}
+}
+
+func (r *Reaper) untrack(p pending) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, p)
+}
+
+func (r *Reaper) tracked() []pending {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]pending, 0, len(r.pending))
+	for p := range r.pending {
+		list = append(list, p)
+	}
+	return list
+}
+
+// Start deletes due topics at every interval until the context is done.
+// Topics scheduled before the reaper started are found by scanning every topic in every pool once.
+func (r *Reaper) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if !r.scanned {
+			if err := r.scan(ctx); err != nil {
+				r.Logger.Errorf("Unable to find topics scheduled for deletion: %s", err)
+			} else {
+				r.scanned = true
+			}
+		}
+		r.reap(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan tracks every topic owned by this cluster that is scheduled for deletion.
+func (r *Reaper) scan(ctx context.Context) error {
+	names, err := r.Pools.Names(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		pool, err := r.Pools.ResolvePool(ctx, name)
+		if err != nil {
+			return fmt.Errorf("resolve kafka service for pool %s: %w", name, err)
+		}
+		topics, err := r.Topics.List(ctx, pool.Project, pool.Service)
+		if err != nil {
+			return fmt.Errorf("list topics in pool %s: %w", name, err)
+		}
+		// The listed topics have no tags, so they are read again in batches to find the scheduled ones.
+		for batch := range slices.Chunk(topics, scanBatchSize) {
+			topicNames := make([]string, 0, len(batch))
+			for _, t := range batch {
+				topicNames = append(topicNames, t.TopicName)
+			}
+			tagged, err := r.Topics.V2List(ctx, pool.Project, pool.Service, topicNames)
+			if err != nil {
+				return fmt.Errorf("get topics in pool %s: %w", name, err)
+			}
+			for _, existing := range tagged {
+				if _, scheduled := topic.DeletionDeadline(existing); scheduled && r.owned(existing) {
+					r.Track(name, existing.TopicName)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// reap deletes the tracked topics that are due, and stops tracking topics that are gone or no longer scheduled.
+func (r *Reaper) reap(ctx context.Context) {
+	counts := make(map[string]int)
+	for _, p := range r.tracked() {
+		logger := r.Logger.WithFields(log.Fields{"pool": p.Pool, "topic": p.Topic})
+		done, err := r.delete(ctx, p, logger)
+		switch {
+		case err != nil:
+			logger.Errorf("Unable to delete topic data: %s", err)
+			counts[p.Pool]++
+		case done:
+			r.untrack(p)
+		default:
+			counts[p.Pool]++
+		}
+	}
+
+	metrics.PendingTopicDeletions.Reset()
+	for pool, count := range counts {
+		metrics.PendingTopicDeletions.With(prometheus.Labels{metrics.LabelPool: pool}).Set(float64(count))
+	}
+}
+
+// delete deletes the topic if its grace period has passed. Returns true if the topic no longer needs tracking.
+func (r *Reaper) delete(ctx context.Context, p pending, logger log.FieldLogger) (bool, error) {
+	pool, err := r.Pools.ResolvePool(ctx, p.Pool)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := r.Topics.Get(ctx, pool.Project, pool.Service, p.Topic)
+	if aiven.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	deadline, scheduled := topic.DeletionDeadline(existing)
+	switch {
+	case !scheduled:
+		logger.Infof("Scheduled deletion of topic data was cancelled")
+		return true, nil
+	case !r.owned(existing):
+		logger.Warnf("Topic was taken over by cluster '%s'; leaving deletion to that cluster", topic.TagValue(existing.Tags, topic.TagCluster))
+		return true, nil
+	case time.Now().Before(deadline):
+		return false, nil
+	}
+
+	kafkaPool, err := r.Pools.Get(ctx, p.Pool)
+	if err != nil {
+		return false, err
+	}
+	if kafkaPool != nil {
+		frozen, err := kafkaPool.Frozen(time.Now())
+		if err != nil {
+			return false, err
+		}
+		if err = frozen.Check(fmt.Sprintf("delete topic %s", p.Topic)); err != nil {
+			logger.Info(err)
+			return false, nil
+		}
+	}
+
+	err = metrics.ObserveAivenLatency("Topic_Delete", pool.Project, func() error {
+		if r.DryRun {
+			logger.Infof("DRY RUN: Would delete Topic: %v", p.Topic)
+			return nil
+		}
+		return r.Topics.Delete(ctx, pool.Project, pool.Service, p.Topic)
+	})
+	if err != nil && !aiven.IsNotFound(err) {
+		return false, err
+	}
+	logger.Infof("Topic and data permanently deleted; grace period ended at %s", deadline.Format(time.RFC3339))
+	return true, nil
+}
+
+func (r *Reaper) owned(existing *aiven.KafkaTopic) bool {
+	return topic.TagValue(existing.Tags, topic.TagCluster) == r.ClusterName
+}
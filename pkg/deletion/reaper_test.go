@@ -0,0 +1,134 @@
+package deletion
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/liberator/pkg/aiven/service"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	"github.com/nais/kafkarator/pkg/kafkapool"
+	"github.com/nais/kafkarator/pkg/metrics"
+)
+
+func scheduled(name, cluster string, deadline time.Time) *aiven.KafkaTopic {
+	return &aiven.KafkaTopic{
+		TopicName: name,
+		Tags: []aiven.KafkaTopicTag{
+			{Key: topic.TagCreatedBy, Value: topic.CreatedByKafkarator},
+			{Key: topic.TagCluster, Value: cluster},
+			{Key: topic.TagDeleteAfter, Value: deadline.Format(time.RFC3339)},
+		},
+	}
+}
+
+func TestReap(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, kafkarator_nais_io_v1alpha1.AddToScheme(scheme))
+
+	nameResolver := service.NewMockNameResolver(t)
+	nameResolver.On("ResolveKafkaServiceName", ctx, "nav-dev").Return("nav-dev-kafka", nil)
+
+	now := time.Now()
+	topics := map[string]*aiven.KafkaTopic{
+		"myteam.due":           scheduled("myteam.due", "test-cluster", now.Add(-time.Minute)),
+		"myteam.pending":       scheduled("myteam.pending", "test-cluster", now.Add(time.Hour)),
+		"myteam.other-cluster": scheduled("myteam.other-cluster", "other-cluster", now.Add(-time.Minute)),
+		"myteam.active":        {TopicName: "myteam.active"},
+	}
+	list := make([]*aiven.KafkaListTopic, 0, len(topics))
+	names := make([]string, 0, len(topics))
+	tagged := make([]*aiven.KafkaTopic, 0, len(topics))
+	for name, kafkaTopic := range topics {
+		list = append(list, &aiven.KafkaListTopic{TopicName: name})
+		names = append(names, name)
+		tagged = append(tagged, kafkaTopic)
+	}
+	topicMock := &topic.MockInterface{}
+	topicMock.Test(t)
+	// Every topic in the pool is read in a single request when scanning.
+	topicMock.On("List", ctx, "nav-dev", "nav-dev-kafka").Return(list, nil).Once()
+	topicMock.On("V2List", ctx, "nav-dev", "nav-dev-kafka", names).Return(tagged, nil).Once()
+	for _, name := range []string{"myteam.due", "myteam.pending"} {
+		topicMock.On("Get", ctx, "nav-dev", "nav-dev-kafka", name).Return(topics[name], nil)
+	}
+	topicMock.On("Delete", ctx, "nav-dev", "nav-dev-kafka", "myteam.due").Return(nil).Once()
+	// Tracked by the reconciler, but deleted in the meantime.
+	topicMock.On("Get", ctx, "nav-dev", "nav-dev-kafka", "myteam.gone").Return(nil, aiven.Error{Status: http.StatusNotFound})
+
+	reaper := &Reaper{
+		Pools: &kafkapool.Registry{
+			Reader:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+			Aiven:    kafkarator_aiven.Interfaces{NameResolver: nameResolver},
+			Projects: []string{"nav-dev"},
+		},
+		Topics:      topicMock,
+		ClusterName: "test-cluster",
+		Logger:      log.New(),
+	}
+	reaper.Track("nav-dev", "myteam.gone")
+
+	require.NoError(t, reaper.scan(ctx))
+	assert.ElementsMatch(t, []pending{
+		{Pool: "nav-dev", Topic: "myteam.due"},
+		{Pool: "nav-dev", Topic: "myteam.pending"},
+		{Pool: "nav-dev", Topic: "myteam.gone"},
+	}, reaper.tracked())
+
+	reaper.reap(ctx)
+	assert.Equal(t, []pending{{Pool: "nav-dev", Topic: "myteam.pending"}}, reaper.tracked())
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.PendingTopicDeletions.WithLabelValues("nav-dev")))
+	topicMock.AssertExpectations(t)
+
+	// Recreating the Topic resource removes the deletion tag, which cancels the deletion.
+	topics["myteam.pending"].Tags = topics["myteam.pending"].Tags[:2]
+	reaper.reap(ctx)
+	assert.Empty(t, reaper.tracked())
+	topicMock.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestReapFrozen(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, kafkarator_nais_io_v1alpha1.AddToScheme(scheme))
+	kafkaPool := &kafkarator_nais_io_v1alpha1.KafkaPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "nav-prod"},
+		Spec: kafkarator_nais_io_v1alpha1.KafkaPoolSpec{
+			Project: "nav-prod",
+			Service: "nav-prod-kafka",
+			Freeze:  &kafkarator_nais_io_v1alpha1.KafkaPoolFreeze{Enabled: true},
+		},
+	}
+
+	topicMock := topic.NewMockInterface(t)
+	topicMock.On("Get", ctx, "nav-prod", "nav-prod-kafka", "myteam.due").
+		Return(scheduled("myteam.due", "test-cluster", time.Now().Add(-time.Minute)), nil)
+
+	reaper := &Reaper{
+		Pools: &kafkapool.Registry{
+			Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(kafkaPool).Build(),
+		},
+		Topics:      topicMock,
+		ClusterName: "test-cluster",
+		Logger:      log.New(),
+	}
+	reaper.Track("nav-prod", "myteam.due")
+
+	// Deletion is deferred while the pool is frozen.
+	reaper.reap(ctx)
+	assert.Equal(t, []pending{{Pool: "nav-prod", Topic: "myteam.due"}}, reaper.tracked())
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.PendingTopicDeletions.WithLabelValues("nav-prod")))
+}
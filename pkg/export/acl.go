@@ -0,0 +1,71 @@
+package export
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nais/kafkarator/pkg/aiven/acl"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+)
+
+var accessModes = []string{"read", "write", "readwrite"}
+
+// ACLs groups the ACLs of a topic into TopicACL entries, by parsing the service user name of each ACL.
+// ACLs that do not match a team and application are listed in the returned notes.
+func ACLs(namespace, topicName string, acls []*acl.Acl) (kafka_nais_io_v1.TopicACLs, []string) {
+	var topicACLs kafka_nais_io_v1.TopicACLs
+	var notes []string
+	for _, a := range acls {
+		if a.Topic != topicName {
+			continue
+		}
+		if !slices.Contains(accessModes, a.Permission) {
+			notes = append(notes, fmt.Sprintf("ACL %s %s: permission is not supported by Kafkarator", a.Username, a.Permission))
+			continue
+		}
+		team, application, ok := parseUsername(namespace, a.Username)
+		if !ok {
+			notes = append(notes, fmt.Sprintf("ACL %s %s: user name does not match a team and application", a.Username, a.Permission))
+			continue
+		}
+		topicACL := kafka_nais_io_v1.TopicACL{
+			Access:      a.Permission,
+			Application: application,
+			Team:        team,
+		}
+		// Legacy and current user names of the same application give the same entry.
+		if !slices.Contains(topicACLs, topicACL) {
+			topicACLs = append(topicACLs, topicACL)
+		}
+	}
+	return topicACLs, notes
+}
+
+// parseUsername finds the team and application of a service user name in an ACL.
+//
+// Current user names are on the form team_application_hash_*, where team and application may be shortened.
+// The hash identifies the full names; shortened names are recovered if the team is the namespace of the topic.
+// Legacy user names are on the form team.application*.
+func parseUsername(namespace, username string) (string, string, bool) {
+	parts := strings.Split(username, "_")
+	if len(parts) != 4 || parts[3] != "*" {
+		legacy, ok := strings.CutSuffix(username, "*")
+		if !ok {
+			return "", "", false
+		}
+		team, application, ok := strings.Cut(legacy, ".")
+		return team, application, ok && len(team) > 0 && len(application) > 0
+	}
+
+	shortTeam, shortApplication := parts[0], parts[1]
+	for _, team := range []string{shortTeam, namespace} {
+		for _, application := range []string{shortApplication, team + "-" + shortApplication} {
+			name, err := kafka_nais_io_v1.ServiceUserNameWithSuffix(team, application, "*")
+			if err == nil && name == username {
+				return team, application, true
+			}
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,159 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	hourMs = int64(60 * 60 * 1000)
+
+	// sourceTopicConfig is the source Aiven reports for settings set on the topic, as opposed to broker defaults.
+	sourceTopicConfig = "topic_config"
+)
+
+// managedSettings are the settings represented in the Topic spec, by the name used by Aiven.
+var managedSettings = []string{
+	"cleanup_policy",
+	"delete_retention_ms",
+	"local_retention_bytes",
+	"local_retention_ms",
+	"max_compaction_lag_ms",
+	"max_message_bytes",
+	"min_cleanable_dirty_ratio",
+	"min_compaction_lag_ms",
+	"min_insync_replicas",
+	"remote_storage_enable",
+	"retention_bytes",
+	"retention_ms",
+	"segment_ms",
+}
+
+type setting struct {
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// Config converts the configuration of a topic in Aiven to a Topic spec configuration, and extra configuration
+// for the settings allowlisted by topic.ExtraConfigNames. Settings that can not be represented exactly are listed
+// in the returned notes.
+func Config(t *aiven.KafkaTopic) (*kafka_nais_io_v1.Config, topic.ExtraConfig, []string, error) {
+	var notes []string
+	note := func(format string, args ...any) {
+		notes = append(notes, fmt.Sprintf(format, args...))
+	}
+
+	integer := func(value *aiven.KafkaTopicConfigResponseInt) *int {
+		if value == nil {
+			return nil
+		}
+		return new(int(value.Value))
+	}
+
+	// Durations are rounded to whole hours. Negative values are only kept if they mean the same in the Topic spec.
+	hours := func(name string, value *aiven.KafkaTopicConfigResponseInt, negative int) *int {
+		if value == nil {
+			return nil
+		}
+		ms := value.Value
+		switch {
+		case ms < 0 && ms == int64(negative):
+			return new(negative)
+		case ms < 0:
+			note("%s: %d can not be represented", name, ms)
+			return nil
+		case ms%hourMs != 0:
+			h := max(1, int(math.Round(float64(ms)/float64(hourMs))))
+			note("%s: %d ms is rounded to %d hours", name, ms, h)
+			return new(h)
+		default:
+			return new(int(ms / hourMs))
+		}
+	}
+
+	c := t.Config
+	cfg := &kafka_nais_io_v1.Config{
+		DeleteRetentionHours:  hours("delete.retention.ms", c.DeleteRetentionMs, 0),
+		LocalRetentionBytes:   integer(c.LocalRetentionBytes),
+		LocalRetentionHours:   hours("local.retention.ms", c.LocalRetentionMs, -2),
+		MaxCompactionLagMs:    integer(c.MaxCompactionLagMs),
+		MaxMessageBytes:       integer(c.MaxMessageBytes),
+		MinCompactionLagMs:    integer(c.MinCompactionLagMs),
+		MinimumInSyncReplicas: integer(c.MinInsyncReplicas),
+		Partitions:            new(len(t.Partitions)),
+		Replication:           new(t.Replication),
+		RetentionBytes:        integer(c.RetentionBytes),
+		RetentionHours:        hours("retention.ms", c.RetentionMs, -1),
+		SegmentHours:          hours("segment.ms", c.SegmentMs, 0),
+	}
+	if c.CleanupPolicy != nil {
+		cfg.CleanupPolicy = new(c.CleanupPolicy.Value)
+	}
+	if c.MinCleanableDirtyRatio != nil {
+		percent := c.MinCleanableDirtyRatio.Value * 100
+		if percent != math.Round(percent) {
+			note("min.cleanable.dirty.ratio: %v is rounded to %.0f%%", c.MinCleanableDirtyRatio.Value, percent)
+		}
+		cfg.MinCleanableDirtyRatioPercent = new(intstr.FromString(fmt.Sprintf("%.0f%%", percent)))
+	}
+
+	settings, err := topicSettings(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	extra := make(map[string]any)
+	for _, name := range slices.Sorted(maps.Keys(settings)) {
+		s := settings[name]
+		kafkaName := strings.ReplaceAll(name, "_", ".")
+		switch {
+		case slices.Contains(managedSettings, name), s.Source != sourceTopicConfig:
+		case slices.Contains(topic.ExtraConfigNames(), kafkaName):
+			extra[kafkaName] = s.Value
+		default:
+			note("%s: %v is not supported by Kafkarator", kafkaName, s.Value)
+		}
+	}
+	if len(extra) == 0 {
+		return cfg, nil, notes, nil
+	}
+
+	data, err := json.Marshal(extra)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	extraConfig, err := topic.ParseExtraConfig(string(data))
+	if err != nil {
+		note("extra configuration %s is not valid: %s", data, err)
+	}
+	return cfg, extraConfig, notes, nil
+}
+
+// topicSettings returns the value and source of every setting reported by Aiven, keyed by the name used by Aiven.
+func topicSettings(cfg aiven.KafkaTopicConfigResponse) (map[string]setting, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[string]setting)
+	if err = json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	for name, s := range settings {
+		if number, ok := s.Value.(float64); ok {
+			s.Value = strconv.FormatFloat(number, 'f', -1, 64)
+			settings[name] = s
+		}
+	}
+	return settings, nil
+}
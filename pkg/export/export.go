@@ -0,0 +1,173 @@
+// Package export generates Topic resources from the state of topics and ACLs in Aiven,
+// to bring topics created outside Kafkarator under version control.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/ghodss/yaml"
+	"github.com/nais/kafkarator/pkg/aiven/acl"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Adopt and extra configuration annotations, as read by the topic controller.
+const (
+	adoptAnnotation       = "kafka.nais.io/adopt"
+	extraConfigAnnotation = "kafka.nais.io/extraConfig"
+)
+
+type Exporter struct {
+	Topics  topic.Interface
+	ACLs    acl.Interface
+	Project string
+	Service string
+	// Pool is written as the pool of the generated topics.
+	Pool string
+}
+
+// Manifest is a generated Topic resource, with the state in Aiven that the resource does not represent.
+type Manifest struct {
+	TopicName string
+	// Topic is nil if the topic name can not be used for a Topic resource.
+	Topic *kafka_nais_io_v1.Topic
+	Notes []string
+}
+
+// Export generates a Topic resource for every topic in the Kafka service, sorted by topic name.
+func (e *Exporter) Export(ctx context.Context) ([]Manifest, error) {
+	topics, err := e.Topics.List(ctx, e.Project, e.Service)
+	if err != nil {
+		return nil, fmt.Errorf("list topics: %w", err)
+	}
+	acls, err := e.ACLs.List(ctx, e.Project, e.Service)
+	if err != nil {
+		return nil, fmt.Errorf("list ACLs: %w", err)
+	}
+
+	names := make([]string, 0, len(topics))
+	for _, t := range topics {
+		names = append(names, t.TopicName)
+	}
+	slices.Sort(names)
+
+	manifests := make([]Manifest, 0, len(names))
+	for _, name := range names {
+		t, err := e.Topics.Get(ctx, e.Project, e.Service, name)
+		if err != nil {
+			return nil, fmt.Errorf("get topic '%s': %w", name, err)
+		}
+		manifest, err := e.manifest(t, acls)
+		if err != nil {
+			return nil, fmt.Errorf("topic '%s': %w", name, err)
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+func (e *Exporter) manifest(t *aiven.KafkaTopic, acls []*acl.Acl) (Manifest, error) {
+	manifest := Manifest{TopicName: t.TopicName}
+
+	namespace, name, ok := strings.Cut(t.TopicName, ".")
+	if !ok {
+		manifest.Notes = []string{"topic name is not on the form namespace.name"}
+		return manifest, nil
+	}
+	invalid := append(validation.IsDNS1123Label(namespace), validation.IsDNS1123Subdomain(name)...)
+	if len(invalid) > 0 {
+		manifest.Notes = invalid
+		return manifest, nil
+	}
+
+	cfg, extra, notes, err := Config(t)
+	if err != nil {
+		return manifest, err
+	}
+	topicACLs, aclNotes := ACLs(namespace, t.TopicName, acls)
+	manifest.Notes = append(notes, aclNotes...)
+
+	manifest.Topic = &kafka_nais_io_v1.Topic{
+		Spec: kafka_nais_io_v1.TopicSpec{
+			Pool:   e.Pool,
+			Config: cfg,
+			ACL:    topicACLs,
+		},
+	}
+	manifest.Topic.APIVersion = kafka_nais_io_v1.GroupVersion.String()
+	manifest.Topic.Kind = "Topic"
+	manifest.Topic.Name = name
+	manifest.Topic.Namespace = namespace
+	manifest.Topic.Labels = map[string]string{"team": namespace}
+	manifest.Topic.Annotations = make(map[string]string)
+	if topic.TagValue(t.Tags, topic.TagCreatedBy) != topic.CreatedByKafkarator {
+		manifest.Topic.Annotations[adoptAnnotation] = "true"
+	}
+	if len(extra) > 0 {
+		data, err := yaml.Marshal(extra)
+		if err != nil {
+			return manifest, err
+		}
+		manifest.Topic.Annotations[extraConfigAnnotation] = string(data)
+	}
+	return manifest, nil
+}
+
+// Write writes the manifests as a multi-document YAML stream. The state in Aiven that a manifest does not represent
+// is written as comments before it.
+func Write(w io.Writer, manifests []Manifest) error {
+	for _, manifest := range manifests {
+		var b strings.Builder
+		b.WriteString("---\n")
+		if manifest.Topic == nil {
+			fmt.Fprintf(&b, "# Topic %s can not be represented as a Topic resource:\n", manifest.TopicName)
+		} else if len(manifest.Notes) > 0 {
+			fmt.Fprintf(&b, "# Not represented in this manifest for topic %s:\n", manifest.TopicName)
+		}
+		for _, note := range manifest.Notes {
+			fmt.Fprintf(&b, "#   - %s\n", note)
+		}
+		if manifest.Topic != nil {
+			data, err := yaml.Marshal(resource{
+				APIVersion: manifest.Topic.APIVersion,
+				Kind:       manifest.Topic.Kind,
+				Metadata: metadata{
+					Name:        manifest.Topic.Name,
+					Namespace:   manifest.Topic.Namespace,
+					Labels:      manifest.Topic.Labels,
+					Annotations: manifest.Topic.Annotations,
+				},
+				Spec: manifest.Topic.Spec,
+			})
+			if err != nil {
+				return err
+			}
+			b.Write(data)
+		}
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resource is a Topic without the fields that are empty in a new resource, such as the creation timestamp and status.
+type resource struct {
+	APIVersion string                     `json:"apiVersion"`
+	Kind       string                     `json:"kind"`
+	Metadata   metadata                   `json:"metadata"`
+	Spec       kafka_nais_io_v1.TopicSpec `json:"spec"`
+}
+
+type metadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
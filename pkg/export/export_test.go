@@ -0,0 +1,108 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nais/kafkarator/pkg/aiven/acl"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	"github.com/nais/kafkarator/pkg/export"
+)
+
+const expectedManifests = `---
+# Topic legacy_topic can not be represented as a Topic resource:
+#   - topic name is not on the form namespace.name
+---
+# Not represented in this manifest for topic myteam.mytopic:
+#   - retention.ms: 5400000 ms is rounded to 2 hours
+#   - unclean.leader.election.enable: true is not supported by Kafkarator
+#   - ACL someone-else admin: permission is not supported by Kafkarator
+#   - ACL myteam_ghost_00000000_* read: user name does not match a team and application
+apiVersion: kafka.nais.io/v1
+kind: Topic
+metadata:
+  annotations:
+    kafka.nais.io/adopt: "true"
+    kafka.nais.io/extraConfig: |
+      compression.type: zstd
+  labels:
+    team: myteam
+  name: mytopic
+  namespace: myteam
+spec:
+  acl:
+  - access: read
+    application: myapplication
+    team: myteam
+  - access: readwrite
+    application: myteam-producer
+    team: myteam
+  - access: write
+    application: legacy
+    team: otherteam
+  config:
+    cleanupPolicy: delete
+    maxMessageBytes: 1048588
+    minCleanableDirtyRatioPercent: 50%
+    partitions: 2
+    replication: 3
+    retentionBytes: -1
+    retentionHours: 2
+  pool: nav-dev
+`
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+
+	topics := topic.NewMockInterface(t)
+	topics.On("List", ctx, "project", "kafka").Return([]*aiven.KafkaListTopic{
+		{TopicName: "myteam.mytopic"},
+		{TopicName: "legacy_topic"},
+	}, nil)
+	topics.On("Get", ctx, "project", "kafka", "legacy_topic").Return(&aiven.KafkaTopic{TopicName: "legacy_topic"}, nil)
+	topics.On("Get", ctx, "project", "kafka", "myteam.mytopic").Return(&aiven.KafkaTopic{
+		TopicName:   "myteam.mytopic",
+		Partitions:  []*aiven.Partition{{Partition: 0}, {Partition: 1}},
+		Replication: 3,
+		Config: aiven.KafkaTopicConfigResponse{
+			CleanupPolicy:               &aiven.KafkaTopicConfigResponseString{Value: "delete", Source: "default_config"},
+			CompressionType:             &aiven.KafkaTopicConfigResponseString{Value: "zstd", Source: "topic_config"},
+			MaxMessageBytes:             &aiven.KafkaTopicConfigResponseInt{Value: 1048588, Source: "default_config"},
+			MessageTimestampType:        &aiven.KafkaTopicConfigResponseString{Value: "CreateTime", Source: "default_config"},
+			MinCleanableDirtyRatio:      &aiven.KafkaTopicConfigResponseFloat{Value: 0.5, Source: "topic_config"},
+			RetentionBytes:              &aiven.KafkaTopicConfigResponseInt{Value: -1, Source: "default_config"},
+			RetentionMs:                 &aiven.KafkaTopicConfigResponseInt{Value: 5400000, Source: "topic_config"},
+			UncleanLeaderElectionEnable: &aiven.KafkaTopicConfigResponseBool{Value: true, Source: "topic_config"},
+		},
+	}, nil)
+
+	acls := acl.NewMockInterface(t)
+	acls.On("List", ctx, "project", "kafka").Return([]*acl.Acl{
+		{Username: "myteam_myapplication_1c62faf5_*", Permission: "read", Topic: "myteam.mytopic"},
+		{Username: "myteam.myapplication*", Permission: "read", Topic: "myteam.mytopic"},
+		{Username: "myteam_producer_ef538054_*", Permission: "readwrite", Topic: "myteam.mytopic"},
+		{Username: "otherteam.legacy*", Permission: "write", Topic: "myteam.mytopic"},
+		{Username: "someone-else", Permission: "admin", Topic: "myteam.mytopic"},
+		{Username: "myteam_ghost_00000000_*", Permission: "read", Topic: "myteam.mytopic"},
+		{Username: "myteam_myapplication_1c62faf5_*", Permission: "read", Topic: "myteam.othertopic"},
+	}, nil)
+
+	exporter := &export.Exporter{
+		Topics:  topics,
+		ACLs:    acls,
+		Project: "project",
+		Service: "kafka",
+		Pool:    "nav-dev",
+	}
+	manifests, err := exporter.Export(ctx)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.Write(&buf, manifests))
+	assert.Equal(t, expectedManifests, buf.String())
+}
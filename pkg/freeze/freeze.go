@@ -0,0 +1,70 @@
+// Package freeze defers changes to Kafka pools during change freezes, such as incidents, maintenance in Aiven or
+// holidays. Reads are not affected, so that differences from the wanted state can still be reported.
+package freeze
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Freeze is a change freeze in effect for a pool.
+type Freeze struct {
+	Pool   string
+	Reason string
+	// Until is the end of the freeze window. Freezes without a window, lifted by hand, have a zero Until.
+	Until time.Time
+}
+
+// DeferredError is returned instead of changing a pool while it is frozen, and lists the deferred changes.
+type DeferredError struct {
+	Freeze  Freeze
+	Changes []string
+}
+
+func (e *DeferredError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "pool '%s' is frozen", e.Freeze.Pool)
+	if !e.Freeze.Until.IsZero() {
+		fmt.Fprintf(&sb, " until %s", e.Freeze.Until.UTC().Format(time.RFC3339))
+	}
+	if len(e.Freeze.Reason) > 0 {
+		fmt.Fprintf(&sb, " (%s)", e.Freeze.Reason)
+	}
+	fmt.Fprintf(&sb, "; deferred changes: %s", strings.Join(e.Changes, ", "))
+	return sb.String()
+}
+
+// Check returns a DeferredError for the changes if the freeze is in effect. A nil freeze allows all changes.
+func (f *Freeze) Check(changes ...string) error {
+	if f == nil || len(changes) == 0 {
+		return nil
+	}
+	return &DeferredError{Freeze: *f, Changes: changes}
+}
+
+// Join merges the changes of deferred errors into one error, or returns nil if there are none.
+func Join(errs ...*DeferredError) error {
+	var joined *DeferredError
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if joined == nil {
+			joined = &DeferredError{Freeze: err.Freeze}
+		}
+		joined.Changes = append(joined.Changes, err.Changes...)
+	}
+	if joined == nil {
+		return nil
+	}
+	return joined
+}
+
+// RequeueAfter returns the time until the freeze window ends, or fallback if the freeze is lifted by hand.
+func (f Freeze) RequeueAfter(now time.Time, fallback time.Duration) time.Duration {
+	if f.Until.IsZero() {
+		return fallback
+	}
+	return max(f.Until.Sub(now), time.Second)
+}
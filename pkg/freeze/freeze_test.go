@@ -0,0 +1,97 @@
+package freeze_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nais/kafkarator/pkg/freeze"
+)
+
+func date(day, hour, minute int) time.Time {
+	// 2026-03-02 is a Monday.
+	return time.Date(2026, time.March, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestScheduleNext(t *testing.T) {
+	for _, test := range []struct {
+		spec string
+		from time.Time
+		next time.Time
+	}{
+		{"*/15 * * * *", date(2, 10, 7), date(2, 10, 15)},
+		{"0 18 * * 1-5", date(2, 18, 0), date(3, 18, 0)},
+		{"0 18 * * 1-5", date(6, 19, 0), date(9, 18, 0)},
+		{"0 0 * * 7", date(2, 0, 0), date(8, 0, 0)},
+		{"30 6,12 1 * *", date(2, 0, 0), time.Date(2026, time.April, 1, 6, 30, 0, 0, time.UTC)},
+		{"0 0 20 12 *", date(2, 0, 0), time.Date(2026, time.December, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", date(2, 0, 0), time.Time{}},
+	} {
+		t.Run(test.spec, func(t *testing.T) {
+			schedule, err := freeze.ParseSchedule(test.spec, time.UTC)
+			require.NoError(t, err)
+			assert.Equal(t, test.next, schedule.Next(test.from))
+		})
+	}
+}
+
+func TestScheduleNextInLocation(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	require.NoError(t, err)
+	schedule, err := freeze.ParseSchedule("0 8 * * *", oslo)
+	require.NoError(t, err)
+	assert.Equal(t, date(2, 7, 0), schedule.Next(date(2, 0, 0)).UTC())
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 5-2 * * *",
+		"*/0 * * * *",
+		"* * 0 * *",
+		"a * * * *",
+	} {
+		_, err := freeze.ParseSchedule(spec, time.UTC)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestScheduleWindowEnd(t *testing.T) {
+	// Windows of two hours starting every weekday at 18:00.
+	schedule, err := freeze.ParseSchedule("0 18 * * 1-5", time.UTC)
+	require.NoError(t, err)
+
+	end, frozen := schedule.WindowEnd(date(2, 19, 30), 2*time.Hour)
+	assert.True(t, frozen)
+	assert.Equal(t, date(2, 20, 0), end)
+
+	_, frozen = schedule.WindowEnd(date(2, 20, 0), 2*time.Hour)
+	assert.False(t, frozen)
+	_, frozen = schedule.WindowEnd(date(2, 17, 59), 2*time.Hour)
+	assert.False(t, frozen)
+
+	// Overlapping windows are joined until the last one ends.
+	end, frozen = schedule.WindowEnd(date(2, 19, 30), 48*time.Hour)
+	assert.True(t, frozen)
+	assert.Equal(t, date(8, 18, 0), end)
+}
+
+func TestDeferredError(t *testing.T) {
+	var notFrozen *freeze.Freeze
+	assert.NoError(t, notFrozen.Check("create topic"))
+
+	f := &freeze.Freeze{Pool: "nav-prod", Reason: "Christmas", Until: date(2, 20, 0)}
+	assert.NoError(t, f.Check())
+	assert.EqualError(t, f.Check("create topic myteam.mytopic"),
+		"pool 'nav-prod' is frozen until 2026-03-02T20:00:00Z (Christmas); deferred changes: create topic myteam.mytopic")
+
+	joined := freeze.Join(nil, &freeze.DeferredError{Freeze: *f, Changes: []string{"a"}}, &freeze.DeferredError{Freeze: *f, Changes: []string{"b"}})
+	assert.Equal(t, &freeze.DeferredError{Freeze: *f, Changes: []string{"a", "b"}}, joined)
+	assert.NoError(t, freeze.Join(nil))
+
+	assert.Equal(t, 30*time.Minute, f.RequeueAfter(date(2, 19, 30), time.Hour))
+	assert.Equal(t, time.Hour, freeze.Freeze{Pool: "nav-prod"}.RequeueAfter(date(2, 19, 30), time.Hour))
+}
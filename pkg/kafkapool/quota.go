@@ -0,0 +1,82 @@
+package kafkapool
+
+import (
+	"context"
+	"fmt"
+
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Usage is the total usage of a namespace in a pool.
+type Usage struct {
+	Topics        int
+	Partitions    int
+	RetainedBytes int64
+	// UnlimitedTopics counts topics without a retentionBytes limit, which are not part of RetainedBytes.
+	UnlimitedTopics int
+}
+
+// Add counts a topic with a configuration where all defaults are applied.
+func (u *Usage) Add(cfg *kafka_nais_io_v1.Config) {
+	u.Topics++
+	u.Partitions += *cfg.Partitions
+	if *cfg.RetentionBytes < 0 {
+		u.UnlimitedTopics++
+		return
+	}
+	u.RetainedBytes += int64(*cfg.RetentionBytes) * int64(*cfg.Partitions)
+}
+
+// Check returns an error describing the first quota exceeded by the usage.
+func (u Usage) Check(quota *kafkarator_nais_io_v1alpha1.NamespaceQuota) error {
+	if quota == nil {
+		return nil
+	}
+	if quota.MaxTopics != nil && u.Topics > *quota.MaxTopics {
+		return fmt.Errorf("namespace would have %d topics, exceeding its quota of %d", u.Topics, *quota.MaxTopics)
+	}
+	if quota.MaxPartitions != nil && u.Partitions > *quota.MaxPartitions {
+		return fmt.Errorf("namespace would have %d partitions, exceeding its quota of %d", u.Partitions, *quota.MaxPartitions)
+	}
+	if quota.MaxRetainedBytes != nil {
+		if u.UnlimitedTopics > 0 {
+			return fmt.Errorf("namespace has a quota for retained bytes, but %d topics have no retentionBytes limit", u.UnlimitedTopics)
+		}
+		if u.RetainedBytes > *quota.MaxRetainedBytes {
+			return fmt.Errorf("namespace would retain %d bytes, exceeding its quota of %d", u.RetainedBytes, *quota.MaxRetainedBytes)
+		}
+	}
+	return nil
+}
+
+// Usage computes the usage of the topic's namespace in the pool, as if the topic was synchronized with its current configuration.
+// Other topics are counted with their desired configuration, read from the cache. Topics that were never synchronized,
+// i.e. because they were refused, do not exist in Aiven and are not counted.
+func (r *Registry) Usage(ctx context.Context, kafkaPool *kafkarator_nais_io_v1alpha1.KafkaPool, topic *kafka_nais_io_v1.Topic) (Usage, error) {
+	topics := &kafka_nais_io_v1.TopicList{}
+	err := r.Reader.List(ctx, topics, client.InNamespace(topic.Namespace))
+	if err != nil {
+		return Usage{}, fmt.Errorf("list topics in namespace '%s': %w", topic.Namespace, err)
+	}
+
+	usage := Usage{}
+	usage.Add(topic.Spec.Config)
+	for _, other := range topics.Items {
+		if other.Name == topic.Name || other.Spec.Pool != topic.Spec.Pool || other.DeletionTimestamp != nil {
+			continue
+		}
+		if other.Status == nil || len(other.Status.SynchronizationHash) == 0 {
+			continue
+		}
+		cfg := &kafka_nais_io_v1.Config{}
+		if other.Spec.Config != nil {
+			cfg = other.Spec.Config.DeepCopy()
+		}
+		kafkaPool.ApplyTopicDefaults(cfg)
+		cfg.ApplyDefaults()
+		usage.Add(cfg)
+	}
+	return usage, nil
+}
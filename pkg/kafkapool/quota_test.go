@@ -0,0 +1,93 @@
+package kafkapool_test
+
+import (
+	"context"
+	"testing"
+
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	"github.com/nais/kafkarator/pkg/kafkapool"
+)
+
+// clusterTopic returns a topic that has been synchronized to Aiven.
+func clusterTopic(namespace, name, pool string, cfg *kafka_nais_io_v1.Config) *kafka_nais_io_v1.Topic {
+	return &kafka_nais_io_v1.Topic{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: kafka_nais_io_v1.TopicSpec{
+			Pool:   pool,
+			Config: cfg,
+		},
+		Status: &kafka_nais_io_v1.TopicStatus{SynchronizationHash: "abc123"},
+	}
+}
+
+func TestUsage(t *testing.T) {
+	kafkaPool := &kafkarator_nais_io_v1alpha1.KafkaPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "nav-dev"},
+		Spec: kafkarator_nais_io_v1alpha1.KafkaPoolSpec{
+			Project:       "nav-dev",
+			TopicDefaults: &kafka_nais_io_v1.Config{RetentionBytes: new(1000)},
+		},
+	}
+	registry := newRegistry(t,
+		clusterTopic("myteam", "first", "nav-dev", &kafka_nais_io_v1.Config{Partitions: new(2)}),
+		clusterTopic("myteam", "second", "nav-dev", &kafka_nais_io_v1.Config{Partitions: new(3), RetentionBytes: new(-1)}),
+		clusterTopic("myteam", "mytopic", "nav-dev", &kafka_nais_io_v1.Config{Partitions: new(100)}),
+		clusterTopic("myteam", "other-pool", "nav-prod", nil),
+		clusterTopic("otherteam", "other-namespace", "nav-dev", nil),
+	)
+
+	topic := clusterTopic("myteam", "mytopic", "nav-dev", &kafka_nais_io_v1.Config{Partitions: new(4), RetentionBytes: new(500)})
+	topic.Spec.Config.ApplyDefaults()
+
+	usage, err := registry.Usage(context.Background(), kafkaPool, topic)
+	require.NoError(t, err)
+	assert.Equal(t, kafkapool.Usage{
+		Topics:          3,
+		Partitions:      9,
+		RetainedBytes:   2*1000 + 4*500,
+		UnlimitedTopics: 1,
+	}, usage)
+
+	assert.NoError(t, usage.Check(&kafkarator_nais_io_v1alpha1.NamespaceQuota{MaxTopics: new(3), MaxPartitions: new(9)}))
+	assert.EqualError(t, usage.Check(&kafkarator_nais_io_v1alpha1.NamespaceQuota{MaxTopics: new(2)}), "namespace would have 3 topics, exceeding its quota of 2")
+	assert.EqualError(t, usage.Check(&kafkarator_nais_io_v1alpha1.NamespaceQuota{MaxRetainedBytes: new(int64(10000))}), "namespace has a quota for retained bytes, but 1 topics have no retentionBytes limit")
+
+	usage.UnlimitedTopics = 0
+	assert.EqualError(t, usage.Check(&kafkarator_nais_io_v1alpha1.NamespaceQuota{MaxRetainedBytes: new(int64(3000))}), "namespace would retain 4000 bytes, exceeding its quota of 3000")
+}
+
+func TestUsageIgnoresRefusedTopics(t *testing.T) {
+	kafkaPool := &kafkarator_nais_io_v1alpha1.KafkaPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "nav-dev"},
+		Spec:       kafkarator_nais_io_v1alpha1.KafkaPoolSpec{Project: "nav-dev"},
+	}
+	quota := &kafkarator_nais_io_v1alpha1.NamespaceQuota{MaxTopics: new(2)}
+
+	refused := clusterTopic("myteam", "refused", "nav-dev", nil)
+	refused.Status = &kafka_nais_io_v1.TopicStatus{SynchronizationState: "FailedPrepare"}
+	registry := newRegistry(t,
+		clusterTopic("myteam", "first", "nav-dev", nil),
+		clusterTopic("myteam", "mytopic", "nav-dev", nil),
+		refused,
+	)
+
+	// The refused topic is over quota, as it would be the third in the namespace.
+	refused.Spec.Config = &kafka_nais_io_v1.Config{}
+	refused.Spec.Config.ApplyDefaults()
+	usage, err := registry.Usage(context.Background(), kafkaPool, refused)
+	require.NoError(t, err)
+	assert.EqualError(t, usage.Check(quota), "namespace would have 3 topics, exceeding its quota of 2")
+
+	// Changing another topic is not blocked by the refused one, which does not exist in Aiven.
+	topic := clusterTopic("myteam", "mytopic", "nav-dev", &kafka_nais_io_v1.Config{})
+	topic.Spec.Config.ApplyDefaults()
+	usage, err = registry.Usage(context.Background(), kafkaPool, topic)
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Topics)
+	assert.NoError(t, usage.Check(quota))
+}
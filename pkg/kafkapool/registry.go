@@ -0,0 +1,127 @@
+// Package kafkapool looks up the Kafka pools that resources in the cluster are allowed to use.
+//
+// Pools are declared as cluster scoped KafkaPool resources. Pools listed in the --projects flag
+// remain usable without a KafkaPool resource, and resolve as configured by --pools.
+package kafkapool
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apimachinery_errors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NotAllowedError is returned when a pool can not be used from a namespace.
+type NotAllowedError struct {
+	Pool      string
+	Namespace string
+	Reason    error
+}
+
+func (e *NotAllowedError) Error() string {
+	if len(e.Namespace) == 0 {
+		return fmt.Sprintf("pool '%s' cannot be used in this cluster", e.Pool)
+	}
+	return fmt.Sprintf("pool '%s' cannot be used from namespace '%s': %s", e.Pool, e.Namespace, e.Reason)
+}
+
+type Registry struct {
+	Reader   client.Reader
+	Aiven    kafkarator_aiven.Interfaces
+	Projects []string
+}
+
+var _ kafkarator_aiven.PoolResolver = &Registry{}
+
+// Get returns the KafkaPool resource with the given name, or nil if there is none.
+func (r *Registry) Get(ctx context.Context, name string) (*kafkarator_nais_io_v1alpha1.KafkaPool, error) {
+	kafkaPool := &kafkarator_nais_io_v1alpha1.KafkaPool{}
+	err := r.Reader.Get(ctx, client.ObjectKey{Name: name}, kafkaPool)
+	switch {
+	case apimachinery_errors.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("unable to retrieve KafkaPool '%s': %w", name, err)
+	}
+	return kafkaPool, nil
+}
+
+// Allowed returns a NotAllowedError if the pool can not be used from the namespace.
+// kafkaPool is nil for pools without a KafkaPool resource, which are allowed only by the --projects flag.
+func (r *Registry) Allowed(ctx context.Context, name, namespace string, kafkaPool *kafkarator_nais_io_v1alpha1.KafkaPool) error {
+	if kafkaPool == nil {
+		if !slices.Contains(r.Projects, name) {
+			return &NotAllowedError{Pool: name}
+		}
+		return nil
+	}
+
+	var namespaceLabels map[string]string
+	if kafkaPool.HasNamespaceSelectors() {
+		ns := &corev1.Namespace{}
+		err := r.Reader.Get(ctx, client.ObjectKey{Name: namespace}, ns)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve namespace '%s': %w", namespace, err)
+		}
+		namespaceLabels = ns.Labels
+	}
+
+	if err := kafkaPool.CheckNamespace(namespace, namespaceLabels); err != nil {
+		return &NotAllowedError{Pool: name, Namespace: namespace, Reason: err}
+	}
+	return nil
+}
+
+// Resolve returns the project and service of a pool. kafkaPool is nil for pools without a KafkaPool resource.
+func (r *Registry) Resolve(ctx context.Context, name string, kafkaPool *kafkarator_nais_io_v1alpha1.KafkaPool) (kafkarator_aiven.Pool, error) {
+	if kafkaPool == nil {
+		return r.Aiven.ResolvePool(ctx, name)
+	}
+
+	serviceName := kafkaPool.Spec.Service
+	if len(serviceName) == 0 {
+		var err error
+		serviceName, err = r.Aiven.NameResolver.ResolveKafkaServiceName(ctx, kafkaPool.Spec.Project)
+		if err != nil {
+			return kafkarator_aiven.Pool{}, err
+		}
+	}
+	return kafkarator_aiven.Pool{
+		Name:    name,
+		Project: kafkaPool.Spec.Project,
+		Service: serviceName,
+	}, nil
+}
+
+func (r *Registry) ResolvePool(ctx context.Context, name string) (kafkarator_aiven.Pool, error) {
+	kafkaPool, err := r.Get(ctx, name)
+	if err != nil {
+		return kafkarator_aiven.Pool{}, err
+	}
+	return r.Resolve(ctx, name, kafkaPool)
+}
+
+func (r *Registry) InvalidatePool(pool kafkarator_aiven.Pool) {
+	r.Aiven.InvalidatePool(pool)
+}
+
+// Names lists every pool known to the cluster, both from KafkaPool resources and the --projects flag.
+func (r *Registry) Names(ctx context.Context) ([]string, error) {
+	kafkaPools := &kafkarator_nais_io_v1alpha1.KafkaPoolList{}
+	err := r.Reader.List(ctx, kafkaPools)
+	if err != nil {
+		return nil, fmt.Errorf("list KafkaPools: %w", err)
+	}
+
+	names := slices.Clone(r.Projects)
+	for _, kafkaPool := range kafkaPools.Items {
+		names = append(names, kafkaPool.Name)
+	}
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
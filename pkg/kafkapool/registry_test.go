@@ -0,0 +1,128 @@
+package kafkapool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nais/liberator/pkg/aiven/service"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	"github.com/nais/kafkarator/pkg/kafkapool"
+)
+
+func newRegistry(t *testing.T, objects ...client.Object) *kafkapool.Registry {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, kafkarator_nais_io_v1alpha1.AddToScheme(scheme))
+	require.NoError(t, kafka_nais_io_v1.AddToScheme(scheme))
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...)
+
+	nameResolver := service.NewMockNameResolver(t)
+	nameResolver.On("ResolveKafkaServiceName", context.Background(), "nav-dev").Return("nav-dev-kafka", nil).Maybe()
+
+	return &kafkapool.Registry{
+		Reader:   clientBuilder.Build(),
+		Aiven:    kafkarator_aiven.Interfaces{NameResolver: nameResolver},
+		Projects: []string{"nav-dev", "nav-prod"},
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	ctx := context.Background()
+	registry := newRegistry(t, &kafkarator_nais_io_v1alpha1.KafkaPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "nav-infrastructure"},
+		Spec: kafkarator_nais_io_v1alpha1.KafkaPoolSpec{
+			Project:           "nav-infrastructure",
+			AllowedNamespaces: []string{"nais-system"},
+		},
+	})
+
+	kafkaPool, err := registry.Get(ctx, "nav-dev")
+	require.NoError(t, err)
+	assert.Nil(t, kafkaPool)
+	assert.NoError(t, registry.Allowed(ctx, "nav-dev", "myteam", kafkaPool))
+
+	kafkaPool, err = registry.Get(ctx, "nav-infrastructure")
+	require.NoError(t, err)
+	require.NotNil(t, kafkaPool)
+	assert.NoError(t, registry.Allowed(ctx, "nav-infrastructure", "nais-system", kafkaPool))
+	assert.EqualError(t, registry.Allowed(ctx, "nav-infrastructure", "myteam", kafkaPool), "pool 'nav-infrastructure' cannot be used from namespace 'myteam': not allowed by any access rule")
+
+	assert.EqualError(t, registry.Allowed(ctx, "nav-unknown", "myteam", nil), "pool 'nav-unknown' cannot be used in this cluster")
+}
+
+func TestAllowedByAccessRules(t *testing.T) {
+	ctx := context.Background()
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	registry := newRegistry(t,
+		namespace("myteam", map[string]string{"environment": "prod"}),
+		namespace("sandbox", map[string]string{"environment": "prod", "sandbox": "true"}),
+		namespace("devteam", map[string]string{"environment": "dev"}),
+	)
+	kafkaPool := &kafkarator_nais_io_v1alpha1.KafkaPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "nav-prod"},
+		Spec: kafkarator_nais_io_v1alpha1.KafkaPoolSpec{
+			Project: "nav-prod",
+			Access: &kafkarator_nais_io_v1alpha1.KafkaPoolAccess{
+				Allow: []kafkarator_nais_io_v1alpha1.NamespaceRule{
+					{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"environment": "prod"}}},
+				},
+				Deny: []kafkarator_nais_io_v1alpha1.NamespaceRule{
+					{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"sandbox": "true"}}},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, registry.Allowed(ctx, "nav-prod", "myteam", kafkaPool))
+	assert.EqualError(t, registry.Allowed(ctx, "nav-prod", "sandbox", kafkaPool), "pool 'nav-prod' cannot be used from namespace 'sandbox': denied by access rule")
+	assert.EqualError(t, registry.Allowed(ctx, "nav-prod", "devteam", kafkaPool), "pool 'nav-prod' cannot be used from namespace 'devteam': not allowed by any access rule")
+
+	var notAllowed *kafkapool.NotAllowedError
+	err := registry.Allowed(ctx, "nav-prod", "unknown", kafkaPool)
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &notAllowed))
+}
+
+func TestResolvePool(t *testing.T) {
+	ctx := context.Background()
+	registry := newRegistry(t, &kafkarator_nais_io_v1alpha1.KafkaPool{
+		ObjectMeta: metav1.ObjectMeta{Name: "nav-fast"},
+		Spec: kafkarator_nais_io_v1alpha1.KafkaPoolSpec{
+			Project: "nav-prod",
+			Service: "kafka-fast",
+		},
+	})
+
+	pool, err := registry.ResolvePool(ctx, "nav-fast")
+	require.NoError(t, err)
+	assert.Equal(t, kafkarator_aiven.Pool{Name: "nav-fast", Project: "nav-prod", Service: "kafka-fast"}, pool)
+
+	pool, err = registry.ResolvePool(ctx, "nav-dev")
+	require.NoError(t, err)
+	assert.Equal(t, kafkarator_aiven.Pool{Name: "nav-dev", Project: "nav-dev", Service: "nav-dev-kafka"}, pool)
+}
+
+func TestNames(t *testing.T) {
+	registry := newRegistry(t,
+		&kafkarator_nais_io_v1alpha1.KafkaPool{ObjectMeta: metav1.ObjectMeta{Name: "nav-fast"}},
+		&kafkarator_nais_io_v1alpha1.KafkaPool{ObjectMeta: metav1.ObjectMeta{Name: "nav-dev"}},
+	)
+
+	names, err := registry.Names(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nav-dev", "nav-fast", "nav-prod"}, names)
+}
@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/kafkapool"
 	"github.com/nais/kafkarator/pkg/metrics"
-	"github.com/nais/liberator/pkg/aiven/service"
 	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -15,10 +15,9 @@ import (
 
 type Acls struct {
 	client.Client
-	projects     []string
-	aiven        *aiven.Client
-	logger       log.FieldLogger
-	nameResolver service.NameResolver
+	aiven  *aiven.Client
+	logger log.FieldLogger
+	pools  *kafkapool.Registry
 }
 
 func (a *Acls) Description() string {
@@ -39,15 +38,19 @@ func (a *Acls) Report(ctx context.Context) error {
 }
 
 func (a *Acls) reportFromAivenProjects(ctx context.Context) error {
-	for _, project := range a.projects {
-		svcName, err := a.nameResolver.ResolveKafkaServiceName(ctx, project)
+	names, err := a.pools.Names(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		pool, err := a.pools.ResolvePool(ctx, name)
 		if err != nil {
-			return fmt.Errorf("resolve kafka service name in project %s: %s", project, err)
+			return fmt.Errorf("resolve kafka service for pool %s: %s", name, err)
 		}
 
-		acls, err := a.aiven.KafkaACLs.List(ctx, project, svcName)
+		acls, err := a.aiven.KafkaACLs.List(ctx, pool.Project, pool.Service)
 		if err != nil {
-			return fmt.Errorf("list acls in in project %s: %s", project, err)
+			return fmt.Errorf("list acls in in project %s: %s", pool.Project, err)
 		}
 
 		topics := make(map[string][]*aiven.KafkaACL)
@@ -58,7 +61,7 @@ func (a *Acls) reportFromAivenProjects(ctx context.Context) error {
 		for topicName, kafkaACLS := range topics {
 			metrics.Acls.With(prometheus.Labels{
 				metrics.LabelTopic:  topicName,
-				metrics.LabelPool:   project,
+				metrics.LabelPool:   name,
 				metrics.LabelSource: metrics.SourceAiven,
 			}).Set(float64(len(kafkaACLS)))
 		}
@@ -3,19 +3,25 @@ package collectors
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/aiven/aiven-go-client/v2"
+	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
+	kafkarator_nais_io_v1alpha1 "github.com/nais/kafkarator/pkg/apis/kafkarator.nais.io/v1alpha1"
+	"github.com/nais/kafkarator/pkg/kafkapool"
 	"github.com/nais/kafkarator/pkg/metrics"
-	"github.com/nais/liberator/pkg/aiven/service"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Metadata struct {
-	projects     []string
-	aiven        *aiven.Client
-	logger       log.FieldLogger
-	nameResolver service.NameResolver
+	client.Client
+	aiven  *aiven.Client
+	logger log.FieldLogger
+	pools  *kafkapool.Registry
+
+	capabilities *kafkarator_aiven.CapabilitiesCache
 }
 
 func (m *Metadata) Description() string {
@@ -27,22 +33,41 @@ func (m *Metadata) Logger() log.FieldLogger {
 }
 
 func (m *Metadata) Report(ctx context.Context) error {
-	for _, project := range m.projects {
-		serviceName, err := m.nameResolver.ResolveKafkaServiceName(ctx, project)
+	names, err := m.pools.Names(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		kafkaPool, err := m.pools.Get(ctx, name)
+		if err != nil {
+			m.logger.Error(err)
+			continue
+		}
+		pool, err := m.pools.Resolve(ctx, name, kafkaPool)
 		if err != nil {
 			m.logger.Errorf(formatError(err))
+			m.updatePoolStatus(ctx, kafkaPool, pool, nil, err)
+			continue
 		}
 		var svc *aiven.Service
-		err = metrics.ObserveAivenLatency("Service_Get", project, func() error {
+		err = metrics.ObserveAivenLatency("Service_Get", pool.Project, func() error {
 			var err error
-			svc, err = m.aiven.Services.Get(ctx, project, serviceName)
+			svc, err = m.aiven.Services.Get(ctx, pool.Project, pool.Service)
 			return err
 		})
+		if aiven.IsNotFound(err) {
+			// The service may have been renamed; resolve it again on next use.
+			m.pools.InvalidatePool(pool)
+		}
 		if err != nil {
 			m.logger.Error(formatError(err))
 		} else {
-			m.reportService(project, svc)
+			m.reportService(name, svc)
+			if m.capabilities != nil {
+				m.capabilities.Update(pool, svc)
+			}
 		}
+		m.updatePoolStatus(ctx, kafkaPool, pool, svc, err)
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
@@ -51,6 +76,33 @@ func (m *Metadata) Report(ctx context.Context) error {
 	return nil
 }
 
+// updatePoolStatus writes the state of the Aiven service to the status of the KafkaPool resource, if any.
+func (m *Metadata) updatePoolStatus(ctx context.Context, kafkaPool *kafkarator_nais_io_v1alpha1.KafkaPool, pool kafkarator_aiven.Pool, svc *aiven.Service, serviceErr error) {
+	if kafkaPool == nil {
+		return
+	}
+
+	status := kafkarator_nais_io_v1alpha1.KafkaPoolStatus{
+		Service:     pool.Service,
+		LastChecked: time.Now().Format(time.RFC3339),
+	}
+	if serviceErr != nil {
+		status.ServiceState = "UNKNOWN"
+		status.Message = formatError(serviceErr)
+	} else {
+		status.ServiceState = svc.State
+		status.KafkaVersion, _ = svc.UserConfig["kafka_version"].(string)
+		status.Plan = svc.Plan
+		status.NodeCount = svc.NodeCount
+	}
+
+	kafkaPool.Status = status
+	err := m.Status().Update(ctx, kafkaPool)
+	if err != nil {
+		m.logger.Errorf("Write KafkaPool %s status: %s", kafkaPool.Name, err)
+	}
+}
+
 func formatError(err error) string {
 	aivenError, ok := err.(aiven.Error)
 	if !ok {
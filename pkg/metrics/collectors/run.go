@@ -5,7 +5,8 @@ import (
 	"time"
 
 	"github.com/aiven/aiven-go-client/v2"
-	"github.com/nais/liberator/pkg/aiven/service"
+	kafkarator_aiven "github.com/nais/kafkarator/pkg/aiven"
+	"github.com/nais/kafkarator/pkg/kafkapool"
 	"github.com/sirupsen/logrus"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -14,35 +15,36 @@ type Opts struct {
 	Client         client.Client
 	AivenClient    *aiven.Client
 	ReportInterval time.Duration
-	Projects       []string
-	NameResolver   service.NameResolver
+	Pools          *kafkapool.Registry
 	Logger         logrus.FieldLogger
+	// Capabilities is refreshed with the services read by the metadata collector, if set.
+	Capabilities *kafkarator_aiven.CapabilitiesCache
 }
 
 func Start(opts *Opts) {
 	topicCollector := &Topic{
-		Client:       opts.Client,
-		projects:     opts.Projects,
-		aiven:        opts.AivenClient,
-		logger:       opts.Logger.WithField("metric-collector", "topic"),
-		nameResolver: opts.NameResolver,
+		Client: opts.Client,
+		aiven:  opts.AivenClient,
+		logger: opts.Logger.WithField("metric-collector", "topic"),
+		pools:  opts.Pools,
 	}
 	go run(topicCollector, opts.ReportInterval)
 
 	metadataCollector := &Metadata{
-		projects:     opts.Projects,
-		aiven:        opts.AivenClient,
-		logger:       opts.Logger.WithField("metric-collector", "metadata"),
-		nameResolver: opts.NameResolver,
+		Client: opts.Client,
+		aiven:  opts.AivenClient,
+		logger: opts.Logger.WithField("metric-collector", "metadata"),
+		pools:  opts.Pools,
+
+		capabilities: opts.Capabilities,
 	}
 	go run(metadataCollector, opts.ReportInterval)
 
 	aclCollector := &Acls{
-		Client:       opts.Client,
-		projects:     opts.Projects,
-		aiven:        opts.AivenClient,
-		logger:       opts.Logger.WithField("metric-collector", "acls"),
-		nameResolver: opts.NameResolver,
+		Client: opts.Client,
+		aiven:  opts.AivenClient,
+		logger: opts.Logger.WithField("metric-collector", "acls"),
+		pools:  opts.Pools,
 	}
 	go run(aclCollector, opts.ReportInterval)
 }
@@ -7,8 +7,8 @@ import (
 
 	"github.com/aiven/aiven-go-client/v2"
 	"github.com/nais/kafkarator/pkg/aiven/topic"
+	"github.com/nais/kafkarator/pkg/kafkapool"
 	"github.com/nais/kafkarator/pkg/metrics"
-	"github.com/nais/liberator/pkg/aiven/service"
 	"github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -17,10 +17,9 @@ import (
 
 type Topic struct {
 	client.Client
-	projects     []string
-	aiven        *aiven.Client
-	logger       *log.Entry
-	nameResolver service.NameResolver
+	aiven  *aiven.Client
+	logger *log.Entry
+	pools  *kafkapool.Registry
 }
 
 func (t *Topic) Description() string {
@@ -89,23 +88,27 @@ func (t *Topic) aivenTopics(ctx context.Context) (map[string][]*aiven.KafkaListT
 	existing := make(map[string][]*aiven.KafkaListTopic)
 
 	// make list of known pools
-	for _, project := range t.projects {
-		existing[project] = nil
+	names, err := t.pools.Names(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		existing[name] = nil
 	}
 
 	// fetch existing topics
-	for pool := range existing {
-		serviceName, err := t.nameResolver.ResolveKafkaServiceName(ctx, pool)
+	for name := range existing {
+		pool, err := t.pools.ResolvePool(ctx, name)
 		if err != nil {
 			return nil, err
 		}
 		topicManager := topic.Manager{
 			AivenTopics: t.aiven.KafkaTopics,
-			Project:     pool,
-			Service:     serviceName,
+			Project:     pool.Project,
+			Service:     pool.Service,
 			Logger:      t.logger.WithContext(ctx),
 		}
-		existing[pool], err = topicManager.List(ctx)
+		existing[name], err = topicManager.List(ctx)
 		if err != nil {
 			return nil, err
 		}
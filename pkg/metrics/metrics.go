@@ -14,7 +14,13 @@ const (
 	LabelAivenOperation = "operation"
 	LabelApp            = "app"
 	LabelGroupID        = "group_id"
+	LabelKind           = "kind"
+	LabelMode           = "mode"
+	LabelName           = "name"
 	LabelPool           = "pool"
+	LabelReason         = "reason"
+	LabelResource       = "resource"
+	LabelRule           = "rule"
 	LabelSource         = "source"
 	LabelStatus         = "status"
 	LabelSyncState      = "synchronization_state"
@@ -27,6 +33,15 @@ const (
 
 	SourceCluster = "cluster"
 	SourceAiven   = "aiven"
+
+	// Topic tag mismatch reasons
+	ReasonOtherCluster = "other_cluster"
+	ReasonOutOfBand    = "out_of_band"
+
+	// Quota resources
+	ResourceTopics        = "topics"
+	ResourcePartitions    = "partitions"
+	ResourceRetainedBytes = "retained_bytes"
 )
 
 var (
@@ -67,6 +82,48 @@ var (
 		Help:      "unwritten secrets for a specific group id",
 	}, []string{LabelGroupID})
 
+	PoolAccessRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "pool_access_rejected",
+		Namespace: Namespace,
+		Help:      "number of resources rejected because their namespace may not use the pool",
+	}, []string{LabelKind, LabelTeam, LabelPool})
+
+	PoolQuotaUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "pool_quota_usage",
+		Namespace: Namespace,
+		Help:      "usage of a namespace quota in a kafka pool",
+	}, []string{LabelTeam, LabelPool, LabelResource})
+
+	PoolQuotaLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "pool_quota_limit",
+		Namespace: Namespace,
+		Help:      "namespace quota in a kafka pool",
+	}, []string{LabelTeam, LabelPool, LabelResource})
+
+	PolicyViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "policy_violations",
+		Namespace: Namespace,
+		Help:      "number of topic configuration policy violations, by the mode of the violated rule",
+	}, []string{LabelPool, LabelRule, LabelMode})
+
+	TopicTagMismatch = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "topic_tag_mismatch",
+		Namespace: Namespace,
+		Help:      "number of topics in aiven with tags showing they are managed from another cluster or changed outside kafkarator",
+	}, []string{LabelPool, LabelReason})
+
+	PendingTopicDeletions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "pending_topic_deletions",
+		Namespace: Namespace,
+		Help:      "number of deleted topics with data scheduled for deletion after the grace period",
+	}, []string{LabelPool})
+
+	PausedResources = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "paused_resources",
+		Namespace: Namespace,
+		Help:      "topics and streams with reconciliation paused by annotation",
+	}, []string{LabelKind, LabelTeam, LabelName})
+
 	PoolNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name:      "kafka_pool_nodes_count",
 		Namespace: Namespace,
@@ -109,6 +166,13 @@ func Register(registry prometheus.Registerer) {
 		Topics,
 		TopicsProcessed,
 		StreamsProcessed,
+		PoolAccessRejected,
+		PoolQuotaUsage,
+		PoolQuotaLimit,
+		PolicyViolations,
+		TopicTagMismatch,
+		PendingTopicDeletions,
+		PausedResources,
 		PoolNodes,
 		PoolInfo,
 	)
@@ -0,0 +1,247 @@
+// Package policy evaluates topic configuration against platform rules, such as retention limits for a pool.
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+type Mode string
+
+const (
+	// ModeEnforce rejects topics violating a rule.
+	ModeEnforce Mode = "enforce"
+	// ModeWarn reports violations, but synchronizes the topic.
+	ModeWarn Mode = "warn"
+)
+
+// Policy is a set of rules for the configuration of topics.
+//
+// An example policy file:
+//
+//	mode: enforce
+//	rules:
+//	  - name: dev-retention
+//	    pools: [nav-dev]
+//	    fields:
+//	      retentionHours: {max: 720}
+//	  - name: compaction-lag
+//	    when:
+//	      cleanupPolicy: [compact, "compact,delete"]
+//	    fields:
+//	      minCompactionLagMs: {required: true, min: 1}
+type Policy struct {
+	// Mode of rules that do not set one. Defaults to enforce.
+	Mode  Mode   `json:"mode,omitempty"`
+	Rules []Rule `json:"rules"`
+}
+
+type Rule struct {
+	Name string `json:"name"`
+	// Pools the rule applies to. The rule applies to every pool if empty.
+	Pools []string `json:"pools,omitempty"`
+	Mode  Mode     `json:"mode,omitempty"`
+	// The rule only applies to topics where every field listed has one of the given values.
+	When map[string][]string `json:"when,omitempty"`
+	// Fields are named as in the topic configuration, i.e. retentionHours.
+	Fields map[string]FieldRule `json:"fields"`
+}
+
+type FieldRule struct {
+	// The field must be set.
+	Required bool   `json:"required,omitempty"`
+	Min      *int64 `json:"min,omitempty"`
+	// Negative values, meaning unlimited, exceed any maximum for retentionHours and retentionBytes.
+	Max     *int64   `json:"max,omitempty"`
+	Allowed []string `json:"allowed,omitempty"`
+}
+
+type Violation struct {
+	Rule    string
+	Mode    Mode
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("policy '%s': %s", v.Rule, v.Message)
+}
+
+// unlimitedFields are fields where negative values mean no limit.
+var unlimitedFields = []string{"retentionHours", "retentionBytes"}
+
+// fields maps the JSON names of the topic configuration to the index of the struct field.
+var fields = func() map[string]int {
+	t := reflect.TypeFor[kafka_nais_io_v1.Config]()
+	fields := make(map[string]int, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[name] = i
+	}
+	return fields
+}()
+
+// Parse reads a policy in YAML or JSON, and validates that it only refers to known fields.
+func Parse(data []byte) (*Policy, error) {
+	policy := &Policy{}
+	err := yaml.Unmarshal(data, policy)
+	if err != nil {
+		return nil, err
+	}
+	if len(policy.Mode) == 0 {
+		policy.Mode = ModeEnforce
+	}
+	if err = validMode(policy.Mode); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range policy.Rules {
+		if len(rule.Name) == 0 {
+			return nil, fmt.Errorf("rule %d has no name", i)
+		}
+		if len(rule.Mode) > 0 {
+			if err = validMode(rule.Mode); err != nil {
+				return nil, fmt.Errorf("rule '%s': %w", rule.Name, err)
+			}
+		}
+		for field := range rule.When {
+			if _, ok := fields[field]; !ok {
+				return nil, fmt.Errorf("rule '%s': unknown field '%s'", rule.Name, field)
+			}
+		}
+		for field := range rule.Fields {
+			if _, ok := fields[field]; !ok {
+				return nil, fmt.Errorf("rule '%s': unknown field '%s'", rule.Name, field)
+			}
+		}
+	}
+	return policy, nil
+}
+
+func validMode(mode Mode) error {
+	if mode != ModeEnforce && mode != ModeWarn {
+		return fmt.Errorf("unknown mode '%s'; expected %s or %s", mode, ModeEnforce, ModeWarn)
+	}
+	return nil
+}
+
+// Evaluate returns the violations of every rule applying to a topic configuration in the pool.
+func (p *Policy) Evaluate(pool string, cfg *kafka_nais_io_v1.Config) []Violation {
+	var violations []Violation
+	for _, rule := range p.Rules {
+		if len(rule.Pools) > 0 && !slices.Contains(rule.Pools, pool) {
+			continue
+		}
+		if !rule.applies(cfg) {
+			continue
+		}
+		mode := rule.Mode
+		if len(mode) == 0 {
+			mode = p.Mode
+		}
+
+		names := make([]string, 0, len(rule.Fields))
+		for name := range rule.Fields {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			message := rule.Fields[name].check(name, value(cfg, name))
+			if len(message) > 0 {
+				violations = append(violations, Violation{
+					Rule:    rule.Name,
+					Mode:    mode,
+					Message: message,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+func (rule Rule) applies(cfg *kafka_nais_io_v1.Config) bool {
+	for name, values := range rule.When {
+		v := value(cfg, name)
+		if v == nil || !slices.Contains(values, v.String()) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r FieldRule) check(name string, v *fieldValue) string {
+	if v == nil {
+		if r.Required {
+			return fmt.Sprintf("%s must be set", name)
+		}
+		return ""
+	}
+	if len(r.Allowed) > 0 && !slices.Contains(r.Allowed, v.String()) {
+		return fmt.Sprintf("%s '%s' is not one of %s", name, v, strings.Join(r.Allowed, ", "))
+	}
+	if r.Min == nil && r.Max == nil {
+		return ""
+	}
+	number, ok := v.Int()
+	if !ok {
+		return fmt.Sprintf("%s '%s' is not a number", name, v)
+	}
+	if r.Max != nil && number < 0 && slices.Contains(unlimitedFields, name) {
+		return fmt.Sprintf("%s must be limited to at most %d", name, *r.Max)
+	}
+	if r.Min != nil && number < *r.Min {
+		return fmt.Sprintf("%s %d is below the minimum of %d", name, number, *r.Min)
+	}
+	if r.Max != nil && number > *r.Max {
+		return fmt.Sprintf("%s %d exceeds the maximum of %d", name, number, *r.Max)
+	}
+	return ""
+}
+
+// fieldValue is the value of a configuration field, which is either a string or an integer.
+type fieldValue struct {
+	str    string
+	number *int64
+}
+
+func (v *fieldValue) String() string {
+	if v.number != nil {
+		return strconv.FormatInt(*v.number, 10)
+	}
+	return v.str
+}
+
+func (v *fieldValue) Int() (int64, bool) {
+	if v.number != nil {
+		return *v.number, true
+	}
+	number, err := strconv.ParseInt(strings.TrimSuffix(v.str, "%"), 10, 64)
+	return number, err == nil
+}
+
+// value returns the value of a field in the configuration, or nil if it is not set.
+func value(cfg *kafka_nais_io_v1.Config, name string) *fieldValue {
+	field := reflect.ValueOf(cfg).Elem().Field(fields[name])
+	if field.IsNil() {
+		return nil
+	}
+	switch v := field.Interface().(type) {
+	case *string:
+		return &fieldValue{str: *v}
+	case *int:
+		return &fieldValue{number: new(int64(*v))}
+	case *intstr.IntOrString:
+		if v.Type == intstr.Int {
+			return &fieldValue{number: new(int64(v.IntValue()))}
+		}
+		return &fieldValue{str: v.StrVal}
+	default:
+		return &fieldValue{str: fmt.Sprint(field.Elem().Interface())}
+	}
+}
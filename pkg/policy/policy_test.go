@@ -0,0 +1,135 @@
+package policy_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/nais/kafkarator/pkg/policy"
+)
+
+const testPolicy = `
+rules:
+  - name: dev-retention
+    pools: [nav-dev]
+    mode: warn
+    fields:
+      retentionHours: {max: 720}
+  - name: compaction-lag
+    when:
+      cleanupPolicy: [compact]
+    fields:
+      minCompactionLagMs: {required: true, min: 1}
+  - name: dirty-ratio
+    fields:
+      minCleanableDirtyRatioPercent: {max: 60}
+      cleanupPolicy: {allowed: [delete, compact]}
+`
+
+func TestParse(t *testing.T) {
+	p, err := policy.Parse([]byte(testPolicy))
+	require.NoError(t, err)
+	assert.Equal(t, policy.ModeEnforce, p.Mode)
+	assert.Len(t, p.Rules, 3)
+
+	_, err = policy.Parse([]byte("rules: [{name: foo, fields: {retention: {max: 1}}}]"))
+	assert.EqualError(t, err, "rule 'foo': unknown field 'retention'")
+
+	_, err = policy.Parse([]byte("mode: strict"))
+	assert.EqualError(t, err, "unknown mode 'strict'; expected enforce or warn")
+
+	_, err = policy.Parse([]byte("rules: [{fields: {}}]"))
+	assert.EqualError(t, err, "rule 0 has no name")
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := policy.Parse([]byte(testPolicy))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name     string
+		pool     string
+		cfg      kafka_nais_io_v1.Config
+		expected []policy.Violation
+	}{
+		{
+			name: "compliant",
+			pool: "nav-dev",
+			cfg:  kafka_nais_io_v1.Config{CleanupPolicy: new("delete"), RetentionHours: new(168)},
+		},
+		{
+			name: "infinite retention exceeds maximum",
+			pool: "nav-dev",
+			cfg:  kafka_nais_io_v1.Config{RetentionHours: new(-1)},
+			expected: []policy.Violation{
+				{Rule: "dev-retention", Mode: policy.ModeWarn, Message: "retentionHours must be limited to at most 720"},
+			},
+		},
+		{
+			name: "rule for other pool",
+			pool: "nav-prod",
+			cfg:  kafka_nais_io_v1.Config{RetentionHours: new(10000)},
+		},
+		{
+			name: "conditional rule",
+			pool: "nav-prod",
+			cfg:  kafka_nais_io_v1.Config{CleanupPolicy: new("compact")},
+			expected: []policy.Violation{
+				{Rule: "compaction-lag", Mode: policy.ModeEnforce, Message: "minCompactionLagMs must be set"},
+			},
+		},
+		{
+			name: "allowed values and percentages",
+			pool: "nav-prod",
+			cfg: kafka_nais_io_v1.Config{
+				CleanupPolicy:                 new("compact,delete"),
+				MinCleanableDirtyRatioPercent: new(intstr.FromString("75%")),
+			},
+			expected: []policy.Violation{
+				{Rule: "dirty-ratio", Mode: policy.ModeEnforce, Message: "cleanupPolicy 'compact,delete' is not one of delete, compact"},
+				{Rule: "dirty-ratio", Mode: policy.ModeEnforce, Message: "minCleanableDirtyRatioPercent 75 exceeds the maximum of 60"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, p.Evaluate(tt.pool, &tt.cfg))
+		})
+	}
+}
+
+func TestStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules: []"), 0o600))
+
+	store, err := policy.NewStore(path, log.New())
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = store.Start(ctx)
+	}()
+
+	cfg := &kafka_nais_io_v1.Config{RetentionHours: new(1000)}
+	assert.Empty(t, store.Evaluate("nav-dev", cfg))
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		// Rewritten until the watcher has started and picked up the change.
+		require.NoError(c, os.WriteFile(path, []byte(testPolicy), 0o600))
+		assert.Len(c, store.Evaluate("nav-dev", cfg), 1)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(path, []byte("mode: strict"), 0o600))
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, store.Evaluate("nav-dev", cfg), 1, "invalid policy should keep the previous policy")
+
+	var nilStore *policy.Store
+	assert.Empty(t, nilStore.Evaluate("nav-dev", cfg))
+}
@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	log "github.com/sirupsen/logrus"
+)
+
+// Store holds the policy read from a file, and reloads it when the file changes.
+// If a changed file is invalid, the previous policy stays in effect.
+type Store struct {
+	path   string
+	logger log.FieldLogger
+	policy atomic.Pointer[Policy]
+}
+
+// NewStore reads the policy file, which must exist and be valid.
+func NewStore(path string, logger log.FieldLogger) (*Store, error) {
+	store := &Store{
+		path:   path,
+		logger: logger,
+	}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Evaluate returns the violations of the current policy. A nil store has no policy.
+func (s *Store) Evaluate(pool string, cfg *kafka_nais_io_v1.Config) []Violation {
+	if s == nil {
+		return nil
+	}
+	return s.policy.Load().Evaluate(pool, cfg)
+}
+
+func (s *Store) load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("read policy file: %w", err)
+	}
+	// Files are empty while being rewritten, and a policy without rules must be explicit.
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fmt.Errorf("policy file '%s' is empty", s.path)
+	}
+	policy, err := Parse(data)
+	if err != nil {
+		return fmt.Errorf("parse policy file '%s': %w", s.path, err)
+	}
+	s.policy.Store(policy)
+	return nil
+}
+
+// Start watches the policy file until the context is done.
+// The directory is watched rather than the file, as mounted ConfigMaps are updated by replacing a symlink.
+func (s *Store) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("watch policy file: %w", err)
+	}
+	defer watcher.Close()
+
+	if err = watcher.Add(filepath.Dir(s.path)); err != nil {
+		return fmt.Errorf("watch policy file: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			if err = s.load(); err != nil {
+				s.logger.Errorf("Keeping previous policy: %s", err)
+				continue
+			}
+			s.logger.Infof("Reloaded policy from %s", s.path)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			s.logger.Errorf("Watching policy file: %s", err)
+		}
+	}
+}
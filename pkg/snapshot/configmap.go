@@ -0,0 +1,106 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apimachinery_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Labels, annotations and data key of snapshot ConfigMaps.
+const (
+	LabelSnapshot      = "kafkarator.nais.io/snapshot"
+	AnnotationTopic    = "kafkarator.nais.io/topic"
+	AnnotationTakenAt  = "kafkarator.nais.io/takenAt"
+	snapshotKey        = "snapshot.json"
+	snapshotNamePrefix = "topic-snapshot-"
+)
+
+// ConfigMapStore keeps each snapshot in a ConfigMap in one namespace.
+// Snapshots older than the retention are deleted when a new snapshot is saved.
+type ConfigMapStore struct {
+	Client client.Client
+	// Reader lists snapshots without a cache, so that ConfigMaps in the cluster are not cached.
+	Reader    client.Reader
+	Namespace string
+	Retention time.Duration
+	Logger    log.FieldLogger
+}
+
+var _ Store = &ConfigMapStore{}
+
+func (s *ConfigMapStore) Save(ctx context.Context, snapshot *Snapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: snapshotNamePrefix,
+			Namespace:    s.Namespace,
+			Labels: map[string]string{
+				LabelSnapshot: "true",
+			},
+			Annotations: map[string]string{
+				AnnotationTopic:   snapshot.Topic,
+				AnnotationTakenAt: snapshot.TakenAt.Format(time.RFC3339),
+			},
+		},
+		Data: map[string]string{snapshotKey: string(data)},
+	}
+	if err = s.Client.Create(ctx, configMap); err != nil {
+		return fmt.Errorf("save snapshot of topic %s: %w", snapshot.Topic, err)
+	}
+	snapshot.Name = configMap.Name
+
+	// The snapshot is saved, and expired snapshots are pruned again with the next one.
+	if err = s.prune(ctx); err != nil {
+		s.Logger.Errorf("Unable to prune snapshots: %s", err)
+	}
+	return nil
+}
+
+// Get returns the snapshot with the given name.
+func (s *ConfigMapStore) Get(ctx context.Context, name string) (*Snapshot, error) {
+	configMap := &corev1.ConfigMap{}
+	err := s.Reader.Get(ctx, client.ObjectKey{Namespace: s.Namespace, Name: name}, configMap)
+	if err != nil {
+		return nil, err
+	}
+	if configMap.Labels[LabelSnapshot] != "true" {
+		return nil, fmt.Errorf("ConfigMap %s/%s is not a snapshot", s.Namespace, name)
+	}
+	snapshot := &Snapshot{Name: name}
+	if err = json.Unmarshal([]byte(configMap.Data[snapshotKey]), snapshot); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s: %w", name, err)
+	}
+	return snapshot, nil
+}
+
+// prune deletes the snapshots taken before the retention.
+func (s *ConfigMapStore) prune(ctx context.Context) error {
+	configMaps := &corev1.ConfigMapList{}
+	err := s.Reader.List(ctx, configMaps, client.InNamespace(s.Namespace), client.MatchingLabels{LabelSnapshot: "true"})
+	if err != nil {
+		return fmt.Errorf("list snapshots: %w", err)
+	}
+	expired := time.Now().Add(-s.Retention)
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		takenAt, err := time.Parse(time.RFC3339, configMap.Annotations[AnnotationTakenAt])
+		if err != nil || takenAt.After(expired) {
+			continue
+		}
+		err = s.Client.Delete(ctx, configMap)
+		if err != nil && !apimachinery_errors.IsNotFound(err) {
+			return fmt.Errorf("delete expired snapshot %s: %w", configMap.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/aiven/acl"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	log "github.com/sirupsen/logrus"
+)
+
+// Restore recreates the topic and its ACLs from a snapshot. A topic that still exists is left as it is, such as
+// topics with data scheduled for deletion, and only the missing ACLs are created.
+func Restore(ctx context.Context, topics topic.Interface, acls acl.Interface, s *Snapshot, logger log.FieldLogger) error {
+	req, err := s.CreateRequest()
+	if err != nil {
+		return err
+	}
+
+	err = topics.Create(ctx, s.Project, s.Service, req)
+	switch {
+	case isConflict(err):
+		logger.Infof("Topic %s already exists; restoring ACLs only", s.Topic)
+	case err != nil:
+		return fmt.Errorf("create topic %s: %w", s.Topic, err)
+	default:
+		logger.Infof("Created topic %s with %d partitions and replication %d", s.Topic, s.Partitions, s.Replication)
+	}
+
+	existing, err := acls.List(ctx, s.Project, s.Service)
+	if err != nil {
+		return fmt.Errorf("list ACLs: %w", err)
+	}
+	for _, a := range s.ACLs {
+		if hasACL(existing, a) {
+			continue
+		}
+		_, err = acls.Create(ctx, s.Project, s.Service, acl.CreateKafkaACLRequest{
+			Permission: a.Permission,
+			Topic:      a.Topic,
+			Username:   a.Username,
+		})
+		if err != nil {
+			return fmt.Errorf("create ACL %s %s on %s: %w", a.Username, a.Permission, a.Topic, err)
+		}
+		logger.Infof("Created ACL %s %s on %s", a.Username, a.Permission, a.Topic)
+	}
+	return nil
+}
+
+func hasACL(acls []*acl.Acl, wanted acl.Acl) bool {
+	for _, a := range acls {
+		if a.Permission == wanted.Permission && a.Topic == wanted.Topic && a.Username == wanted.Username {
+			return true
+		}
+	}
+	return false
+}
+
+func isConflict(err error) bool {
+	aivenErr, ok := err.(aiven.Error)
+	return ok && aivenErr.Status == http.StatusConflict
+}
@@ -0,0 +1,100 @@
+// Package snapshot records the state of topics in Aiven before they are deleted, so that a deleted topic can be
+// recreated with the same configuration and ACLs. The data of a deleted topic can not be restored.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	"github.com/nais/kafkarator/pkg/aiven/acl"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+)
+
+// Snapshot is the state of a topic in Aiven, with the ACLs that apply to it.
+type Snapshot struct {
+	// Name identifies a stored snapshot, and is set by the store.
+	Name        string                         `json:"-"`
+	Project     string                         `json:"project"`
+	Service     string                         `json:"service"`
+	Topic       string                         `json:"topic"`
+	Reason      string                         `json:"reason"`
+	TakenAt     time.Time                      `json:"takenAt"`
+	Partitions  int                            `json:"partitions"`
+	Replication int                            `json:"replication"`
+	Config      aiven.KafkaTopicConfigResponse `json:"config"`
+	Tags        []aiven.KafkaTopicTag          `json:"tags,omitempty"`
+	ACLs        []acl.Acl                      `json:"acls,omitempty"`
+}
+
+type Store interface {
+	Save(ctx context.Context, snapshot *Snapshot) error
+}
+
+// New returns a snapshot of the topic, with the ACLs that apply to it either by name or by wildcard.
+func New(project, service string, existing *aiven.KafkaTopic, acls []*acl.Acl, reason string) *Snapshot {
+	snapshot := &Snapshot{
+		Project:     project,
+		Service:     service,
+		Topic:       existing.TopicName,
+		Reason:      reason,
+		TakenAt:     time.Now().UTC().Truncate(time.Second),
+		Partitions:  len(existing.Partitions),
+		Replication: existing.Replication,
+		Config:      existing.Config,
+		Tags:        existing.Tags,
+	}
+	for _, a := range acls {
+		prefix, wildcard := strings.CutSuffix(a.Topic, "*")
+		if a.Topic == existing.TopicName || (wildcard && strings.HasPrefix(existing.TopicName, prefix)) {
+			snapshot.ACLs = append(snapshot.ACLs, *a)
+		}
+	}
+	return snapshot
+}
+
+// CreateRequest returns a request to recreate the topic with the settings that were set on the topic,
+// as opposed to broker defaults.
+func (s *Snapshot) CreateRequest() (aiven.CreateKafkaTopicRequest, error) {
+	data, err := json.Marshal(s.Config)
+	if err != nil {
+		return aiven.CreateKafkaTopicRequest{}, err
+	}
+	settings := make(map[string]struct {
+		Value  any    `json:"value"`
+		Source string `json:"source"`
+	})
+	if err = json.Unmarshal(data, &settings); err != nil {
+		return aiven.CreateKafkaTopicRequest{}, err
+	}
+
+	values := make(map[string]any)
+	for name, setting := range settings {
+		if setting.Source == "topic_config" {
+			values[name] = setting.Value
+		}
+	}
+	req := aiven.CreateKafkaTopicRequest{
+		TopicName:   s.Topic,
+		Partitions:  &s.Partitions,
+		Replication: &s.Replication,
+	}
+	data, err = json.Marshal(values)
+	if err == nil {
+		err = json.Unmarshal(data, &req.Config)
+	}
+	if err != nil {
+		return aiven.CreateKafkaTopicRequest{}, fmt.Errorf("topic configuration: %w", err)
+	}
+
+	// A restored topic is no longer scheduled for deletion.
+	for _, tag := range s.Tags {
+		if tag.Key != topic.TagDeleteAfter {
+			req.Tags = append(req.Tags, tag)
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,114 @@
+package snapshot_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aiven/aiven-go-client/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/nais/kafkarator/pkg/aiven/acl"
+	"github.com/nais/kafkarator/pkg/aiven/topic"
+	"github.com/nais/kafkarator/pkg/snapshot"
+)
+
+func existingTopic() *aiven.KafkaTopic {
+	t := &aiven.KafkaTopic{
+		TopicName:   "myteam.mytopic",
+		Replication: 3,
+		Partitions:  []*aiven.Partition{{Partition: 0}, {Partition: 1}},
+		Tags: []aiven.KafkaTopicTag{
+			{Key: topic.TagCreatedBy, Value: topic.CreatedByKafkarator},
+			{Key: topic.TagDeleteAfter, Value: "2026-01-01T00:00:00Z"},
+		},
+	}
+	t.Config.RetentionMs = &aiven.KafkaTopicConfigResponseInt{Source: "topic_config", Value: 3600000}
+	t.Config.SegmentMs = &aiven.KafkaTopicConfigResponseInt{Source: "default_config", Value: 604800000}
+	t.Config.CleanupPolicy = &aiven.KafkaTopicConfigResponseString{Source: "topic_config", Value: "compact"}
+	return t
+}
+
+var acls = []*acl.Acl{
+	{ID: "acl-1", Permission: "read", Topic: "myteam.mytopic", Username: "myteam_consumer_4e7a10b6_*"},
+	{ID: "acl-2", Permission: "write", Topic: "myteam.my*", Username: "myteam_producer_50d4b2c4_*"},
+	{ID: "acl-3", Permission: "read", Topic: "myteam.othertopic", Username: "myteam_consumer_4e7a10b6_*"},
+}
+
+func TestNew(t *testing.T) {
+	s := snapshot.New("nav-dev", "nav-dev-kafka", existingTopic(), acls, "Topic myteam/mytopic deleted")
+	assert.Equal(t, 2, s.Partitions)
+	assert.Equal(t, 3, s.Replication)
+	assert.Equal(t, []acl.Acl{*acls[0], *acls[1]}, s.ACLs)
+
+	req, err := s.CreateRequest()
+	require.NoError(t, err)
+	assert.Equal(t, "myteam.mytopic", req.TopicName)
+	assert.Equal(t, 2, *req.Partitions)
+	assert.Equal(t, 3, *req.Replication)
+	assert.Equal(t, aiven.KafkaTopicConfig{CleanupPolicy: "compact", RetentionMs: new(int64(3600000))}, req.Config)
+	assert.Equal(t, []aiven.KafkaTopicTag{{Key: topic.TagCreatedBy, Value: topic.CreatedByKafkarator}}, req.Tags)
+}
+
+func TestConfigMapStore(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	expired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "topic-snapshot-expired",
+			Namespace:   "kafkarator",
+			Labels:      map[string]string{snapshot.LabelSnapshot: "true"},
+			Annotations: map[string]string{snapshot.AnnotationTakenAt: time.Now().Add(-time.Hour * 48).Format(time.RFC3339)},
+		},
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(expired).Build()
+	store := &snapshot.ConfigMapStore{
+		Client:    kubeClient,
+		Reader:    kubeClient,
+		Namespace: "kafkarator",
+		Retention: time.Hour * 24,
+		Logger:    log.New(),
+	}
+
+	s := snapshot.New("nav-dev", "nav-dev-kafka", existingTopic(), acls, "Topic myteam/mytopic deleted")
+	require.NoError(t, store.Save(ctx, s))
+	require.NotEmpty(t, s.Name)
+
+	saved, err := store.Get(ctx, s.Name)
+	require.NoError(t, err)
+	assert.Equal(t, s, saved)
+
+	configMaps := &corev1.ConfigMapList{}
+	require.NoError(t, kubeClient.List(ctx, configMaps, client.InNamespace("kafkarator")))
+	require.Len(t, configMaps.Items, 1)
+	assert.Equal(t, s.Name, configMaps.Items[0].Name)
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	s := snapshot.New("nav-dev", "nav-dev-kafka", existingTopic(), acls, "Topic myteam/mytopic deleted")
+	req, err := s.CreateRequest()
+	require.NoError(t, err)
+
+	topicMock := topic.NewMockInterface(t)
+	topicMock.On("Create", ctx, "nav-dev", "nav-dev-kafka", req).Return(aiven.Error{Status: http.StatusConflict})
+	aclMock := acl.NewMockInterface(t)
+	aclMock.On("List", ctx, "nav-dev", "nav-dev-kafka").Return(acls[1:], nil)
+	aclMock.On("Create", ctx, "nav-dev", "nav-dev-kafka", acl.CreateKafkaACLRequest{
+		Permission: "read",
+		Topic:      "myteam.mytopic",
+		Username:   "myteam_consumer_4e7a10b6_*",
+	}).Return(&acl.Acl{}, nil)
+
+	require.NoError(t, snapshot.Restore(ctx, topicMock, aclMock, s, log.New()))
+}
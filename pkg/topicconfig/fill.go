@@ -0,0 +1,34 @@
+// Package topicconfig combines topic configuration from the sources Kafkarator reads it from.
+package topicconfig
+
+import (
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+)
+
+// Fill sets every unset field in cfg to a copy of the same field in defaults, if set.
+func Fill(cfg, defaults *kafka_nais_io_v1.Config) {
+	if defaults == nil {
+		return
+	}
+	fill(&cfg.CleanupPolicy, defaults.CleanupPolicy)
+	fill(&cfg.DeleteRetentionHours, defaults.DeleteRetentionHours)
+	fill(&cfg.MinimumInSyncReplicas, defaults.MinimumInSyncReplicas)
+	fill(&cfg.Partitions, defaults.Partitions)
+	fill(&cfg.Replication, defaults.Replication)
+	fill(&cfg.RetentionBytes, defaults.RetentionBytes)
+	fill(&cfg.RetentionHours, defaults.RetentionHours)
+	fill(&cfg.LocalRetentionBytes, defaults.LocalRetentionBytes)
+	fill(&cfg.LocalRetentionHours, defaults.LocalRetentionHours)
+	fill(&cfg.SegmentHours, defaults.SegmentHours)
+	fill(&cfg.MaxMessageBytes, defaults.MaxMessageBytes)
+	fill(&cfg.MinCompactionLagMs, defaults.MinCompactionLagMs)
+	fill(&cfg.MaxCompactionLagMs, defaults.MaxCompactionLagMs)
+	fill(&cfg.MinCleanableDirtyRatioPercent, defaults.MinCleanableDirtyRatioPercent)
+}
+
+func fill[T any](field **T, dflt *T) {
+	if *field == nil && dflt != nil {
+		value := *dflt
+		*field = &value
+	}
+}
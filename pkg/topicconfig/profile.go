@@ -0,0 +1,48 @@
+package topicconfig
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+)
+
+// Profile is a named topic configuration for a common use case, such as a compacted state store.
+//
+// Profiles are kept in a ConfigMap with one key for each pool, holding the profiles of the pool:
+//
+//	data:
+//	  nav-dev: |
+//	    state-store:
+//	      version: "2"
+//	      config:
+//	        cleanupPolicy: compact
+//	        retentionHours: -1
+type Profile struct {
+	// Version is part of the synchronization hash, so that topics are synchronized when the profile changes.
+	Version string                  `json:"version"`
+	Config  kafka_nais_io_v1.Config `json:"config"`
+}
+
+// LookupProfile finds a profile of a pool in the data of the profiles ConfigMap.
+func LookupProfile(data map[string]string, pool, name string) (*Profile, error) {
+	profiles := make(map[string]Profile)
+	if err := yaml.Unmarshal([]byte(data[pool]), &profiles); err != nil {
+		return nil, fmt.Errorf("parse profiles of pool '%s': %w", pool, err)
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile '%s' is not defined for pool '%s'", name, pool)
+	}
+	if len(profile.Version) == 0 {
+		return nil, fmt.Errorf("profile '%s' of pool '%s' has no version", name, pool)
+	}
+	return &profile, nil
+}
+
+// ApplyProfile sets every unset field in cfg to the setting of the profile, so that the topic's own settings take precedence.
+func ApplyProfile(cfg *kafka_nais_io_v1.Config, profile *Profile) {
+	if profile != nil {
+		Fill(cfg, &profile.Config)
+	}
+}
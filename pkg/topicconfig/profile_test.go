@@ -0,0 +1,41 @@
+package topicconfig_test
+
+import (
+	"testing"
+
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nais/kafkarator/pkg/topicconfig"
+)
+
+func TestLookupProfile(t *testing.T) {
+	data := map[string]string{
+		"nav-dev": `
+state-store:
+  version: "2"
+  config:
+    cleanupPolicy: compact
+    retentionHours: -1
+unversioned:
+  config:
+    partitions: 1
+`,
+	}
+
+	profile, err := topicconfig.LookupProfile(data, "nav-dev", "state-store")
+	require.NoError(t, err)
+	assert.Equal(t, "2", profile.Version)
+
+	cfg := &kafka_nais_io_v1.Config{RetentionHours: new(168)}
+	topicconfig.ApplyProfile(cfg, profile)
+	assert.Equal(t, "compact", *cfg.CleanupPolicy)
+	assert.Equal(t, 168, *cfg.RetentionHours, "the topic's own settings take precedence")
+
+	_, err = topicconfig.LookupProfile(data, "nav-prod", "state-store")
+	assert.EqualError(t, err, "profile 'state-store' is not defined for pool 'nav-prod'")
+
+	_, err = topicconfig.LookupProfile(data, "nav-dev", "unversioned")
+	assert.EqualError(t, err, "profile 'unversioned' of pool 'nav-dev' has no version")
+}